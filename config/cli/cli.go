@@ -0,0 +1,403 @@
+// Package cli implements the config command, a tool to validate, show, compare, describe and convert
+// configuration files without starting the application, i.e on pre-deploy hooks.
+//
+// The stock command, cmd/config, knows no configuration types so it works with the files' generic contents.
+// To work with the application's types, build a program which registers them and calls the `Main`:
+//
+//	func init() {
+//		cli.Register("myapp.Config", func() interface{} { return myapp.NewConfig() })
+//	}
+//
+//	func main() {
+//		cli.Main()
+//	}
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kataras/pkg/config"
+)
+
+var (
+	mu    sync.RWMutex
+	types = make(map[string]func() interface{})
+)
+
+// Register registers a configuration type by its "name", i.e "myapp.Config",
+// so it can be used by the commands' --type flag. The "factory" should return
+// a new pointer to a struct value of the type, filled with its defaults.
+// When a single type is registered the --type flag is optional.
+//
+// Call it before the `Main` (or `Run`), i.e on an init function.
+func Register(name string, factory func() interface{}) {
+	mu.Lock()
+	types[name] = factory
+	mu.Unlock()
+}
+
+func registered() []string {
+	mu.RLock()
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// newDest returns a new configuration of the registered type, if "name" is empty
+// then the only registered type is used, if any, otherwise it returns nil.
+func newDest(name string) (interface{}, error) {
+	mu.RLock()
+	factory, ok := types[name]
+	if name == "" && len(types) == 1 {
+		for _, factory = range types {
+			ok = true
+		}
+	}
+	mu.RUnlock()
+
+	if name == "" && !ok {
+		return nil, nil
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q, registered types: [%s]", name, strings.Join(registered(), ", "))
+	}
+
+	return factory(), nil
+}
+
+const usage = `Usage: config <command> [arguments]
+
+Commands:
+  validate <file>...  decode the files, check the required fields and run the type's validators
+  show <file>         print the effective configuration, secrets and sensitive values are redacted
+  diff <a> <b>        print the differences of two files, exits with 1 if they differ and 2 on errors
+  schema              print the JSON Schema of the type
+  convert [<file>]    convert a file, or the standard input, to another format
+
+Run 'config <command> -h' for the command's flags.
+`
+
+// errUsage is returned by the commands when their arguments are wrong.
+var errUsage = errors.New("usage")
+
+type command func(args []string, stdout, stderr io.Writer) (int, error)
+
+var commands = map[string]command{
+	"validate": validate,
+	"show":     show,
+	"diff":     diff,
+	"schema":   schema,
+	"convert":  convert,
+}
+
+// Main runs the command line of the program and exits with its code, see `Run`.
+func Main() {
+	os.Exit(Run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Run runs the command of the "args", without the program's name, i.e "validate", "config.yml",
+// and returns the exit code; 0 on success, 1 on failure and 2 on wrong usage.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "config: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	code, err := cmd(args[1:], stdout, stderr)
+	if err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+
+		fmt.Fprintf(stderr, "config %s: %v\n", args[0], err)
+		if code == 0 {
+			code = 1
+		}
+	}
+
+	return code
+}
+
+type flags struct {
+	set *flag.FlagSet
+
+	typ       string
+	format    string
+	envPrefix string
+}
+
+func newFlags(name, args string, stderr io.Writer) *flags {
+	f := &flags{set: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.set.SetOutput(stderr)
+	f.set.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: config %s %s\n\nFlags:\n", name, args)
+		f.set.PrintDefaults()
+	}
+	return f
+}
+
+func (f *flags) withType() *flags {
+	f.set.StringVar(&f.typ, "type", "", "the registered configuration type, i.e myapp.Config")
+	return f
+}
+
+func (f *flags) withFormat() *flags {
+	f.set.StringVar(&f.format, "format", "", "the files' format, defaults to their extension")
+	return f
+}
+
+func (f *flags) withEnv() *flags {
+	f.set.StringVar(&f.envPrefix, "env", "", "load the missing fields from the environment variables with this prefix too")
+	return f
+}
+
+// parse parses the flags, even if they are written after the positional arguments,
+// and returns the positional ones.
+func (f *flags) parse(args []string, min, max int) ([]string, error) {
+	var positional []string
+	for {
+		if err := f.set.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if args = f.set.Args(); len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) < min || max >= 0 && len(positional) > max {
+		f.set.Usage()
+		return nil, errUsage
+	}
+
+	return positional, nil
+}
+
+func (f *flags) formatOf(filename string) string {
+	if f.format != "" {
+		return f.format
+	}
+
+	return strings.TrimPrefix(filepath.Ext(filename), ".")
+}
+
+// load loads a file to the "dest" without asking anything,
+// the warnings are written to the "stderr".
+func (f *flags) load(filename string, dest interface{}, stderr io.Writer, extra ...config.Option) error {
+	opts := append([]config.Option{
+		config.WithoutSurvey,
+		config.WithFormat(f.formatOf(filename)),
+		config.WithLogger(log.New(stderr, filename+": ", 0)),
+	}, extra...)
+
+	if f.envPrefix != "" {
+		opts = append(opts, config.WithEnv(f.envPrefix))
+	}
+
+	return config.Load(filename, dest, opts...)
+}
+
+func validate(args []string, stdout, stderr io.Writer) (int, error) {
+	f := newFlags("validate", "<file>... [--type name]", stderr).withType().withFormat().withEnv()
+	files, err := f.parse(args, 1, -1)
+	if err != nil {
+		return 2, err
+	}
+
+	code := 0
+	for _, filename := range files {
+		dest, err := newDest(f.typ)
+		if err != nil {
+			return 2, err
+		}
+
+		if dest == nil {
+			_, err = readTree(filename, f.formatOf(filename))
+		} else {
+			// the missing fields are reported too, the file is not valid without them.
+			err = f.load(filename, dest, stderr, config.WithRequired)
+		}
+
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", filename, err)
+			code = 1
+			continue
+		}
+
+		fmt.Fprintf(stdout, "%s: ok\n", filename)
+	}
+
+	return code, nil
+}
+
+func show(args []string, stdout, stderr io.Writer) (int, error) {
+	f := newFlags("show", "<file> --type name [--output yaml]", stderr).withType().withFormat().withEnv()
+	output := f.set.String("output", "yaml", "the output format: yaml, json, env or flags")
+	files, err := f.parse(args, 1, 1)
+	if err != nil {
+		return 2, err
+	}
+
+	dest, err := requiredDest(f.typ)
+	if err != nil {
+		return 2, err
+	}
+
+	if err = f.load(files[0], dest, stderr); err != nil {
+		return 1, err
+	}
+
+	return 0, config.Dump(stdout, dest, *output)
+}
+
+func requiredDest(typ string) (interface{}, error) {
+	dest, err := newDest(typ)
+	if err == nil && dest == nil {
+		err = fmt.Errorf("the --type flag is required, registered types: [%s]", strings.Join(registered(), ", "))
+	}
+
+	return dest, err
+}
+
+func diff(args []string, stdout, stderr io.Writer) (int, error) {
+	f := newFlags("diff", "<a> <b> [--type name] [--output text]", stderr).withType().withFormat()
+	output := f.set.String("output", "text", "the output format: text or json")
+	files, err := f.parse(args, 2, 2)
+	if err != nil {
+		return 2, err
+	}
+
+	var changes []config.Change
+
+	a, err := newDest(f.typ)
+	if err != nil {
+		return 2, err
+	}
+
+	if a == nil {
+		// without a type the secrets can't be known, they are shown as they are.
+		treeA, err := readTree(files[0], f.formatOf(files[0]))
+		if err != nil {
+			return 2, err
+		}
+
+		treeB, err := readTree(files[1], f.formatOf(files[1]))
+		if err != nil {
+			return 2, err
+		}
+
+		changes = diffTrees(treeA, treeB)
+	} else {
+		b, _ := newDest(f.typ)
+		if err = f.load(files[0], a, stderr); err != nil {
+			return 2, err
+		}
+
+		if err = f.load(files[1], b, stderr); err != nil {
+			return 2, err
+		}
+
+		changes = config.Diff(a, b)
+	}
+
+	if err = config.WriteChanges(stdout, changes, *output); err != nil {
+		return 2, err
+	}
+
+	if len(changes) > 0 {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+func schema(args []string, stdout, stderr io.Writer) (int, error) {
+	f := newFlags("schema", "--type name [--format yaml]", stderr).withType()
+	f.set.StringVar(&f.format, "format", "yaml", "the format of the files that the schema describes")
+	if _, err := f.parse(args, 0, 0); err != nil {
+		return 2, err
+	}
+
+	dest, err := requiredDest(f.typ)
+	if err != nil {
+		return 2, err
+	}
+
+	b, err := config.Schema(dest, f.format)
+	if err != nil {
+		return 1, err
+	}
+
+	_, err = stdout.Write(b)
+	return 0, err
+}
+
+func convert(args []string, stdout, stderr io.Writer) (int, error) {
+	f := newFlags("convert", "[<file>] [--from yaml] --to json [--type name]", stderr)
+	from := f.set.String("from", "", "the input's format: yaml, json, toml or env, defaults to the file's extension")
+	to := f.set.String("to", "", "the output's format: yaml, json, toml or env, required")
+	typ := f.set.String("type", "", "convert through the registered configuration type, the unknown keys are dropped")
+	files, err := f.parse(args, 0, 1)
+	if err != nil {
+		return 2, err
+	}
+
+	if *to == "" {
+		f.set.Usage()
+		return 2, errUsage
+	}
+
+	in := io.Reader(os.Stdin)
+	if len(files) == 1 && files[0] != "-" {
+		file, err := os.Open(files[0])
+		if err != nil {
+			return 1, err
+		}
+		defer file.Close()
+
+		in = file
+		if *from == "" {
+			*from = strings.TrimPrefix(filepath.Ext(files[0]), ".")
+		}
+	}
+
+	if *from == "" {
+		f.set.Usage()
+		return 2, errUsage
+	}
+
+	if *typ != "" {
+		dest, err := requiredDest(*typ)
+		if err != nil {
+			return 2, err
+		}
+
+		return 0, config.ConvertStruct(in, *from, stdout, *to, dest)
+	}
+
+	return 0, config.Convert(in, *from, stdout, *to)
+}
@@ -0,0 +1,107 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kataras/pkg/config/cli"
+)
+
+type testDB struct {
+	Host     string `yaml:"host" json:"host"`
+	Password string `yaml:"password" json:"password" config:"secret"`
+}
+
+type testConfig struct {
+	Addr     string `yaml:"addr" json:"addr" help:"the address to listen on"`
+	MaxConns int    `yaml:"max_conns" json:"max_conns"`
+	DB       testDB `yaml:"db" json:"db"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.MaxConns < 0 {
+		return errors.New("max_conns should be positive")
+	}
+
+	return nil
+}
+
+func init() {
+	cli.Register("cli_test.Config", func() interface{} { return &testConfig{Addr: ":8080"} })
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func run(args ...string) (int, string, string) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	code := cli.Run(args, stdout, stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yml":       "addr: :8080\nmax_conns: 10\ndb:\n  host: localhost\n  password: \"123\"",
+		"b.yml":       "addr: :9090\nmax_conns: 10\ndb:\n  host: localhost\n  password: \"456\"",
+		"bad.yml":     "max_conns: -1",
+		"partial.yml": "addr: :8080\nmax_conns: 10\ndb:\n  password: \"123\"",
+		"c.json":      `{"addr": ":8080", "max_conns": 10, "db": {"host": "localhost", "password": "123"}}`,
+	})
+	a, b, bad, c := filepath.Join(dir, "a.yml"), filepath.Join(dir, "b.yml"), filepath.Join(dir, "bad.yml"), filepath.Join(dir, "c.json")
+	partial := filepath.Join(dir, "partial.yml")
+
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"validate", a, c}, 0, a + ": ok\n" + c + ": ok\n"},
+		{[]string{"validate", bad, "--type", "cli_test.Config"}, 1, ""},
+		{[]string{"validate", partial, "--type", "cli_test.Config"}, 1, ""},
+		{[]string{"validate", a, "--type", "cli_test.Config"}, 0, a + ": ok\n"},
+		{[]string{"validate", a, "--type", "unknown"}, 2, ""},
+		{[]string{"show", a, "--output", "env"}, 0, "ADDR=:8080\nMAX_CONNS=10\nDB_HOST=localhost\nDB_PASSWORD=\"******\"\n"},
+		{[]string{"diff", a, c}, 0, ""},
+		{[]string{"diff", a, b}, 1, "~ addr: \":8080\" -> \":9090\"\n~ db.password: \"******\" -> \"******\"\n"},
+		{[]string{"convert", c, "--to", "yaml"}, 0, "addr: :8080\ndb:\n  host: localhost\n  password: \"123\"\nmax_conns: 10\n"},
+		{[]string{"unknown"}, 2, ""},
+	}
+
+	for _, tt := range tests {
+		code, stdout, stderr := run(tt.args...)
+		if tt.code != code {
+			t.Fatalf("%v: expected exit code %d but got %d: %s", tt.args, tt.code, code, stderr)
+		}
+
+		if tt.stdout != stdout {
+			t.Fatalf("%v: expected output:\n%s\nbut got:\n%s", tt.args, tt.stdout, stdout)
+		}
+	}
+}
+
+func TestRunSchema(t *testing.T) {
+	code, stdout, stderr := run("schema", "--format", "json")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 but got %d: %s", code, stderr)
+	}
+
+	for _, expected := range []string{`"max_conns": {`, `"default": ":8080"`, `"description": "the address to listen on"`, `"writeOnly": true`} {
+		if !strings.Contains(stdout, expected) {
+			t.Fatalf("expected the schema to contain %s but got:\n%s", expected, stdout)
+		}
+	}
+}
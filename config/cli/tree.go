@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"sort"
+
+	"github.com/kataras/pkg/config"
+)
+
+// readTree decodes a file to a generic tree, its objects are map[string]interface{} values.
+func readTree(filename, format string) (interface{}, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeTree(data, format)
+}
+
+// decodeTree decodes the contents of any registered format through their json representation.
+func decodeTree(data []byte, format string) (interface{}, error) {
+	buf := new(bytes.Buffer)
+	if err := config.Convert(bytes.NewReader(data), format, buf, "json"); err != nil {
+		return nil, err
+	}
+
+	var tree interface{}
+	err := json.Unmarshal(buf.Bytes(), &tree)
+	return tree, err
+}
+
+// flattenTree returns the leaves of a tree by their dotted paths, i.e "db.host".
+func flattenTree(prefix string, v interface{}, leaves map[string]interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		if prefix != "" {
+			leaves[prefix] = v
+		}
+		return
+	}
+
+	for key, elem := range m {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flattenTree(key, elem, leaves)
+	}
+}
+
+// diffTrees returns the changes of the leaves of two trees, sorted by their paths.
+func diffTrees(a, b interface{}) (changes []config.Change) {
+	oldLeaves, newLeaves := make(map[string]interface{}), make(map[string]interface{})
+	flattenTree("", a, oldLeaves)
+	flattenTree("", b, newLeaves)
+
+	for path, oldValue := range oldLeaves {
+		newValue, ok := newLeaves[path]
+		switch {
+		case !ok:
+			changes = append(changes, config.Change{Path: path, Kind: config.ChangeRemoved, Old: oldValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			changes = append(changes, config.Change{Path: path, Kind: config.ChangeModified, Old: oldValue, New: newValue})
+		}
+	}
+
+	for path, newValue := range newLeaves {
+		if _, ok := oldLeaves[path]; !ok {
+			changes = append(changes, config.Change{Path: path, Kind: config.ChangeAdded, New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return
+}
@@ -0,0 +1,14 @@
+// Command config validates, shows, compares, describes and converts configuration files.
+// It knows no configuration types, see the config/cli package to build one with the application's types.
+//
+// $ go install github.com/kataras/pkg/config/cmd/config@latest
+// $ config validate config.yml
+// $ config diff config.yml config.prod.yml
+// $ config convert config.yml --to json > config.json
+package main
+
+import "github.com/kataras/pkg/config/cli"
+
+func main() {
+	cli.Main()
+}
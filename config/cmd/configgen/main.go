@@ -0,0 +1,164 @@
+// Command configgen writes a sample, commented, configuration file of a configuration struct
+// or, with the -init flag, asks for its fields' values and writes the answers to a new configuration file.
+//
+// It generates a temporary program inside the module of the struct's package
+// which calls the `config.Template` (or `config.Init`), so the package should not be a main one
+// and its module should require the github.com/kataras/pkg/config module.
+//
+// $ go install github.com/kataras/pkg/config/cmd/configgen@latest
+// $ configgen -pkg ./internal/app -type Configuration -new NewConfiguration > config.example.yml
+// $ configgen -pkg ./internal/app -type Configuration -format env -o .env.example
+// $ configgen -pkg ./internal/app -type Configuration -init -o config.yml
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+type generator struct {
+	ImportPath string
+	Type       string
+	New        string
+	Format     string
+	Output     string
+	Init       bool
+}
+
+func main() {
+	var (
+		g   generator
+		pkg string
+	)
+
+	flag.StringVar(&pkg, "pkg", ".", "the directory or the import path of the package which declares the configuration struct")
+	flag.StringVar(&g.Type, "type", "", "the name of the configuration struct, required")
+	flag.StringVar(&g.New, "new", "", "the name of the package's function which returns the configuration with its defaults, optional")
+	flag.StringVar(&g.Format, "format", "yaml", "the format of the sample file: yaml, env or json")
+	flag.StringVar(&g.Output, "o", "", "the output file, defaults to the standard output or to config.yml on -init")
+	flag.BoolVar(&g.Init, "init", false, "ask for the fields' values and write the answers to the output file")
+	flag.Parse()
+
+	if g.Type == "" {
+		fmt.Fprintln(os.Stderr, "configgen: the -type flag is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if g.Init && g.Output == "" {
+		g.Output = "config.yml"
+	}
+
+	if err := run(pkg, g); err != nil {
+		fmt.Fprintf(os.Stderr, "configgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkg string, g generator) error {
+	out, err := exec.Command("go", "list", "-f", "{{.ImportPath}}\t{{.Module.Dir}}", pkg).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("go list %s: %s", pkg, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return err
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(string(out)), "\t", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("package %s is not part of a module", pkg)
+	}
+	g.ImportPath = parts[0]
+	moduleDir := parts[1]
+
+	// the output is relative to the caller's working directory, not the module's one.
+	if g.Output != "" && !filepath.IsAbs(g.Output) {
+		if g.Output, err = filepath.Abs(g.Output); err != nil {
+			return err
+		}
+	}
+
+	// a directory which starts with a dot is ignored by the "./..." patterns of the module.
+	dir, err := ioutil.TempDir(moduleDir, ".configgen-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	src := new(bytes.Buffer)
+	if err = program.Execute(src, g); err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "main.go"), src.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Dir = moduleDir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+var program = template.Must(template.New("main").Parse(`// Code generated by configgen. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/kataras/pkg/config"
+
+	target "{{.ImportPath}}"
+)
+
+func main() {
+	var dest interface{} = new(target.{{.Type}})
+	{{- if .New}}
+	dest = pointer(target.{{.New}}())
+	{{- end}}
+
+	if err := generate(dest); err != nil {
+		fmt.Fprintf(os.Stderr, "configgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(dest interface{}) error {
+	{{- if .Init}}
+	return config.Init({{printf "%q" .Output}}, dest)
+	{{- else}}
+	b, err := config.Template(dest, {{printf "%q" .Format}})
+	if err != nil {
+		return err
+	}
+	{{if .Output}}
+	return os.WriteFile({{printf "%q" .Output}}, b, 0644)
+	{{- else}}
+	_, err = os.Stdout.Write(b)
+	return err
+	{{- end}}
+	{{- end}}
+}
+
+func pointer(v interface{}) interface{} {
+	if val := reflect.ValueOf(v); val.Kind() != reflect.Ptr {
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		return ptr.Interface()
+	}
+
+	return v
+}
+`))
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// splitList splits a list's value by commas, i.e "a,b,c".
+// An item can be double-quoted to contain commas, i.e `a,"b,c"`,
+// and a quote inside a quoted item is escaped by another one, i.e `"say ""hi"""`.
+// The spaces before the items are trimmed.
+// The value is masked on errors if the "f" field is secret or sensitive, see `maskValue`.
+func splitList(f field, s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	r := csv.NewReader(strings.NewReader(s))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+
+	items, err := r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			err = parseErr.Err
+		}
+		return nil, fmt.Errorf("invalid list %q: %w", maskValue(f, s), err)
+	}
+
+	return items, nil
+}
+
+// joinList is the opposite of the `splitList`, items are quoted only if needed.
+func joinList(items []string) string {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	w.Write(items)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// splitEntry splits a map's entry, i.e "key=value", the entry is masked on errors like the `splitList`'s value.
+func splitEntry(f field, item string) (string, string, error) {
+	idx := strings.IndexByte(item, '=')
+	if idx <= 0 {
+		return "", "", fmt.Errorf("invalid map entry %q: expected key=value", maskValue(f, item))
+	}
+
+	return item[:idx], item[idx+1:], nil
+}
+
+// parseList parses a list of values, see `splitList`, to a slice of the "typ".
+// Fails if any of the values can't be parsed to the slice's element type.
+func parseList(got string, f field, typ reflect.Type) (interface{}, error) {
+	items, err := splitList(f, got)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return reflect.Zero(typ).Interface(), nil // nil, not empty.
+	}
+
+	slice := reflect.MakeSlice(typ, 0, len(items))
+	for i, item := range items {
+		elem := reflect.New(typ.Elem()).Elem()
+		if err = assignString(elem, f, item); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		slice = reflect.Append(slice, elem)
+	}
+
+	return slice.Interface(), nil
+}
+
+// parseMap parses a list of key=value entries, see `splitList`, to a map of the "typ".
+// Fails if the map's keys are not strings or any of the entries can't be parsed.
+func parseMap(got string, f field, typ reflect.Type) (interface{}, error) {
+	if typ.Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+
+	items, err := splitList(f, got)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return reflect.Zero(typ).Interface(), nil // nil, not empty.
+	}
+
+	m := reflect.MakeMapWithSize(typ, len(items))
+	for _, item := range items {
+		key, value, err := splitEntry(f, item)
+		if err != nil {
+			return nil, err
+		}
+
+		elem := reflect.New(typ.Elem()).Elem()
+		if err = assignString(elem, f, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		m.SetMapIndex(reflect.ValueOf(key).Convert(typ.Key()), elem)
+	}
+
+	return m.Interface(), nil
+}
+
+// formatList returns the text representation of a slice or a map's value, the opposite of the `parseList` and `parseMap`.
+func formatList(f field, v reflect.Value) string {
+	var items []string
+
+	switch v.Kind() {
+	case reflect.Map:
+		keys := v.MapKeys()
+		items = make([]string, 0, len(keys))
+		for _, key := range keys {
+			items = append(items, fmt.Sprintf("%v=%s", key.Interface(), formatValue(f, v.MapIndex(key))))
+		}
+		sort.Strings(items)
+	default:
+		items = make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, formatValue(f, v.Index(i)))
+		}
+	}
+
+	return joinList(items)
+}
+
+// isCollection reports whether a field's value is a list or a map of values, []byte is not.
+func isCollection(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Map || (typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8)
+}
+
+// listFlag is the flag's value of a slice or a map field,
+// each time the flag is set its items are appended, i.e "-peers a -peers b,c" results to "a,b,c".
+// Map entries are written as key=value, i.e "-labels env=prod -labels team=core".
+// It implements the standard and the spf13/pflag flag values.
+type listFlag struct {
+	field   field
+	items   []string
+	changed bool
+	isMap   bool
+}
+
+func newListFlag(f field, fValue reflect.Value) *listFlag {
+	value := &listFlag{field: f, isMap: fValue.Kind() == reflect.Map}
+	if def := flagDefault(f, fValue); def != "" {
+		value.items, _ = splitList(f, def)
+	}
+
+	return value
+}
+
+func (v *listFlag) String() string {
+	if v == nil {
+		return ""
+	}
+
+	return joinList(v.items)
+}
+
+func (v *listFlag) Set(s string) error {
+	items, err := splitList(v.field, s)
+	if err != nil {
+		return err
+	}
+
+	if v.isMap {
+		for _, item := range items {
+			if _, _, err = splitEntry(v.field, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !v.changed {
+		// the defaults are replaced.
+		v.items = nil
+		v.changed = true
+	}
+
+	v.items = append(v.items, items...)
+	return nil
+}
+
+// Type implements the spf13/pflag.Value interface.
+func (v *listFlag) Type() string {
+	if v.isMap {
+		return "key=value"
+	}
+
+	return "strings"
+}
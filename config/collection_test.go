@@ -0,0 +1,226 @@
+package config_test
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+
+	"github.com/spf13/pflag"
+)
+
+type testPeer struct {
+	Host string
+	Port int
+}
+
+type testListsConfiguration struct {
+	Peers  []string          `short:"p"`
+	Ports  []int             `yaml:"ports"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+func TestBindFlagsLists(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+
+	c := testListsConfiguration{Peers: []string{"default"}}
+	if err := BindFlags(set, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "default", set.Lookup("peers").DefValue; expected != got {
+		t.Fatalf("expected default value: %s but got: %s", expected, got)
+	}
+
+	args := []string{
+		"-peers", "a", "-p", `b,"c,d"`, "-peers", `"say ""hi"""`,
+		"-ports", "80", "-ports", "443",
+		"-labels", "env=prod", "-labels", `team=core,"note=a,b"`,
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	c = testListsConfiguration{}
+	report := configtest.Load(t, "", &c, WithFileDecoder(nil), WithFlags(set), WithoutSurvey)
+	configtest.AssertLoaded(t, c, testListsConfiguration{
+		Peers:  []string{"a", "b", "c,d", `say "hi"`},
+		Ports:  []int{80, 443},
+		Labels: map[string]string{"env": "prod", "team": "core", "note": "a,b"},
+	})
+	configtest.AssertSource(t, report, "Peers", "flag")
+}
+
+func TestBindFlagsListsInvalid(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+
+	var c testListsConfiguration
+	if err := BindFlags(set, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := set.Parse([]string{"-labels", "env"}); err == nil {
+		t.Fatalf("expected an error for a map entry without a value")
+	}
+
+	if err := set.Parse([]string{"-peers", `"a`}); err == nil {
+		t.Fatalf("expected an error for an unterminated quote")
+	}
+}
+
+func TestBindPFlagsLists(t *testing.T) {
+	set := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	var c testListsConfiguration
+	if err := BindPFlags(set, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := set.Parse([]string{"-p", "a", "--peers", `"b,c"`, "--labels", "env=prod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	configtest.Load(t, "", &c, WithFileDecoder(nil), WithFlagSource(PFlags(set)), WithoutSurvey)
+	configtest.AssertLoaded(t, c, testListsConfiguration{
+		Peers:  []string{"a", "b,c"},
+		Labels: map[string]string{"env": "prod"},
+	})
+}
+
+func TestLoadEnvLists(t *testing.T) {
+	t.Setenv("APP_PEERS", `a,"b,c"`)
+	t.Setenv("APP_PORTS_0", "80")
+	t.Setenv("APP_PORTS_1", "443")
+	t.Setenv("APP_PORTS_3", "8080") // not contiguous, ignored.
+	t.Setenv("APP_LABELS", "env=prod")
+
+	var c testListsConfiguration
+	report := configtest.Load(t, "", &c, WithFileDecoder(nil), WithEnv("APP"), WithoutSurvey)
+	configtest.AssertLoaded(t, c, testListsConfiguration{
+		Peers:  []string{"a", "b,c"},
+		Ports:  []int{80, 443},
+		Labels: map[string]string{"env": "prod"},
+	})
+	configtest.AssertSource(t, report, "ports", "env")
+}
+
+type testNodesConfiguration struct {
+	Nodes []testPeer
+}
+
+func TestLoadEnvIndexedStructs(t *testing.T) {
+	t.Setenv("APP_NODES_0_HOST", "a.local")
+	t.Setenv("APP_NODES_0_PORT", "80")
+	t.Setenv("APP_NODES_1_HOST", "b.local")
+
+	var c testNodesConfiguration
+	if err := TryLoadEnv("APP", &c); err != nil {
+		t.Fatal(err)
+	}
+
+	configtest.AssertLoaded(t, c, testNodesConfiguration{
+		Nodes: []testPeer{{Host: "a.local", Port: 80}, {Host: "b.local"}},
+	})
+}
+
+type testUpstream struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+func (u *testUpstream) Validate() error {
+	if u.Port > 65535 {
+		return InvalidFields(fmt.Errorf("port out of range: %d", u.Port), "Port")
+	}
+
+	return nil
+}
+
+type testBackend struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token" config:"secret"`
+}
+
+type testElementsConfiguration struct {
+	Upstreams []testUpstream         `yaml:"upstreams"`
+	Backends  map[string]testBackend `yaml:"backends"`
+}
+
+func TestLoadElements(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": `upstreams:
+  - host: a.local
+  - host: b.local
+    port: 70000
+backends:
+  api:
+    token: "123"
+  web:
+    url: http://web.local
+`})
+	t.Setenv("APP_BACKENDS_API_URL", "http://api.local")
+	t.Setenv("APP_BACKENDS_WEB_TOKEN", "456")
+
+	var c testElementsConfiguration
+	report := configtest.Load(t, "config.yml", &c, WithFS(fsys), WithEnv("APP"),
+		WithPrompter(configtest.Answers(map[string]string{"upstreams[0].port": "80", "upstreams[1].port": "81"})))
+
+	configtest.AssertLoaded(t, c, testElementsConfiguration{
+		Upstreams: []testUpstream{{Host: "a.local", Port: 80}, {Host: "b.local", Port: 81}},
+		Backends: map[string]testBackend{
+			"api": {URL: "http://api.local", Token: "123"},
+			"web": {URL: "http://web.local", Token: "456"},
+		},
+	})
+	configtest.AssertSource(t, report, "upstreams[0].host", "file")
+	configtest.AssertSource(t, report, "upstreams[0].port", "prompt")
+	configtest.AssertSource(t, report, "backends.api.url", "env")
+
+	err := Load("config.yml", &testElementsConfiguration{}, WithFS(fsys), WithoutSurvey)
+
+	var verrs *ValidationErrors
+	if !errors.As(err, &verrs) || verrs.Errors[0].Path != "upstreams[1]" || verrs.Errors[0].Fields[0] != "upstreams[1].port" {
+		t.Fatalf("expected a validation error of upstreams[1].port but got: %v", err)
+	}
+}
+
+func TestElementsDiffRedacted(t *testing.T) {
+	a := testElementsConfiguration{
+		Upstreams: []testUpstream{{Host: "a.local", Port: 80}},
+		Backends:  map[string]testBackend{"api": {URL: "http://api.local", Token: "123"}},
+	}
+	b := testElementsConfiguration{
+		Upstreams: []testUpstream{{Host: "a.local", Port: 8080}, {Host: "b.local", Port: 81}},
+		Backends:  map[string]testBackend{"api": {URL: "http://api.local", Token: "456"}},
+	}
+
+	var changes []string
+	for _, change := range Diff(a, b) {
+		changes = append(changes, change.String())
+	}
+
+	expected := []string{
+		"~ upstreams[0].port: 80 -> 8080",
+		`~ backends.api.token: "******" -> "******"`,
+		`+ upstreams[1].host: "b.local"`,
+		"+ upstreams[1].port: 81",
+	}
+	if got := strings.Join(changes, "\n"); strings.Join(expected, "\n") != got {
+		t.Fatalf("expected changes:\n%s\nbut got:\n%s", strings.Join(expected, "\n"), got)
+	}
+
+	safe := Redacted(a).(testElementsConfiguration)
+	if expected, got := "******", safe.Backends["api"].Token; expected != got {
+		t.Fatalf("expected redacted token but got: %s", got)
+	}
+
+	if expected, got := "123", a.Backends["api"].Token; expected != got {
+		t.Fatalf("expected the original token to be kept but got: %s", got)
+	}
+}
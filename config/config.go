@@ -1,13 +1,13 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"flag"
-	"io/ioutil"
-	"path/filepath"
+	"fmt"
+	"io/fs"
 	"reflect"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/kataras/pkg/zerocheck"
@@ -26,12 +26,43 @@ import (
 // All options are prefixed with the words: "With" or "Without".
 type options struct {
 	disableSurvey bool
+	// if true then the missing fields fail the load even if the file exists.
+	required bool
 	// if not nil then it will scan for flags after the file loading, file is always first but it can be disabled.
-	flagSet     *flag.FlagSet
+	flags       FlagSource
 	fileDecoder FileDecoder
-	/// TODO:
-	// if users ask then it will be a good idea
-	// to add a loader via os environment variables as well.
+	prompter    Prompter
+	// if not nil then the file is read from that file system instead of the operating system's one.
+	fsys fs.FS
+	// if not empty then the file is searched inside these directories.
+	searchPaths []string
+	// if not nil then it's filled with the fields' sources.
+	report *Report
+	// the rules of the fields' names.
+	naming naming
+	// if true then it will scan for environment variables after the flags.
+	envEnabled bool
+	envPrefix  string
+	// if not nil then it will scan the key/value store right after the file loading.
+	kv       KVSource
+	kvPrefix string
+	// the registered format's name of the file decoder, empty if it's a custom one.
+	format string
+	// if not nil then the file's contents are upgraded before decoded.
+	migrations          *Migrations
+	migrationsWriteBack bool
+	// prints the warnings, if nil then they are only reported.
+	logger Logger
+	// the fields that were provided by the sources so far, even with zero values.
+	provided presence
+	// if not nil then the missing secret fields are read from it before the survey.
+	secrets SecretStore
+	// the invalid answers that are accepted for a single question.
+	maxAttempts int
+	// the functions which are called on each stage.
+	hooks []Hooks
+	// if not nil then it's called with the errors of the failed reloads of the `Store.Watch`.
+	reloadErrorHandler func(err error)
 }
 
 // Option should be implement by all options, it's used to set the `options`.
@@ -43,6 +74,13 @@ func WithoutSurvey(o *options) {
 	o.disableSurvey = true
 }
 
+// WithRequired makes `Load` to return a `*MissingFieldsError` when the sources did not provide all of the fields,
+// even if the configuration file exists, i.e to validate a file along with `WithoutSurvey`.
+// Defaults to false; the missing fields are reported only when the file does not exist.
+func WithRequired(o *options) {
+	o.required = true
+}
+
 // CommandLine flagset is a shortcut for the `flag.CommandLine`
 // so end-users wont have to import the flag package to use the `WithFlags`.
 var CommandLine = flag.CommandLine
@@ -50,14 +88,49 @@ var CommandLine = flag.CommandLine
 // WithFlags enables the config to be loaded from specific flag set( i.e flag.CommandLine).
 // The flags may or may not be parsed before.
 //
-// Note that the end-user should declare the needed flags otherwise this will PANIC (at least for now).
+// Note that the end-user should declare the needed flags, see `BindFlags`, otherwise they are skipped.
 // It scans the flags after the file decoder and before the survey; loading from file is always first action but it can be disabled if needed.
 func WithFlags(set *flag.FlagSet) Option {
+	return WithFlagSource(StdFlags(set))
+}
+
+// WithFlagSource same as `WithFlags` but it accepts any `FlagSource`,
+// i.e `PFlags(cmd.Flags())` for spf13/pflag and cobra commands, see `WithCommand` too.
+func WithFlagSource(src FlagSource) Option {
+	return func(o *options) {
+		o.flags = src
+	}
+}
+
+// Logger is the interface which is used to print warnings and information about the loading,
+// i.e deprecated fields. The standard `*log.Logger` implements it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithLogger sets the logger of the warnings and information about the loading,
+// i.e `log.New(os.Stderr, "config: ", log.LstdFlags)`, pass nil to disable it.
+// The warnings are kept on the `Report` even without a logger, see `WithReport`.
+//
+// Defaults to nil, nothing is logged.
+func WithLogger(logger Logger) Option {
 	return func(o *options) {
-		o.flagSet = set
+		o.logger = logger
+	}
+}
+
+func (o options) logf(format string, v ...interface{}) {
+	if o.logger != nil {
+		o.logger.Printf(format, v...)
 	}
 }
 
+// warnf prints and reports a warning.
+func (o options) warnf(format string, v ...interface{}) {
+	o.logf(format, v...)
+	o.report.warn(fmt.Sprintf(format, v...))
+}
+
 // FileDecoder is the supported kind of function that
 // are allowed to be passed as custom function to decode file's contents
 // and unmarshal to a specific configuration struct
@@ -72,6 +145,7 @@ type FileDecoder func(fileContents []byte, dest interface{}) (err error)
 func WithFileDecoder(fileDecoder FileDecoder) Option {
 	return func(o *options) {
 		o.fileDecoder = fileDecoder
+		o.format = ""
 	}
 }
 
@@ -84,8 +158,7 @@ func ok(dest interface{}) bool {
 	}
 	// no indevitual fields are allowed only pointers to struct.
 	typ := reflect.TypeOf(dest)
-	typElem := typ.Elem() // the struct's type.
-	if typ.Kind() != reflect.Ptr || typElem.Kind() != reflect.Struct {
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
 		return false
 	}
 
@@ -98,27 +171,62 @@ func ok(dest interface{}) bool {
 // If the configuration file didn't contain any sensetive fields
 // and the fields are not tagged as 'config:"-"' then
 // it prompts the user to define these fields' values from
-// the `os.Stdin` using the survey package.
+// the `os.Stdin` using the survey package, see `WithPrompter` too.
 //
 // The "dest" should be a pointer to a struct value
 // and may be filled before this call.
 //
 // Returns an error if something bad happened like
-// bad yaml-formated file.
+// bad yaml-formated file or a failed `Validator`.
+// The errors are typed, see `FileError`, `DecodeError`, `ConversionError`, `PromptError`,
+// `ValidationErrors` and `MissingFieldsError`, and the ones of different sources are joined (see `errors.Join`),
+// use `errors.As` to inspect them. A missing configuration file is not an error
+// when the rest of the sources provided all of the fields.
+//
+// When the flags are enabled, the `ConfigFlag`, i.e "-c config.yml -c config.prod.yml", selects the files instead of the "fullpath",
+// the next ones override the values of the previous ones, and the `ConfigFormatFlag` their format,
+// otherwise their extension is used; the first one's format names the fields of all of the sources.
+// When the environment variables are enabled, the APP_CONFIG and APP_CONFIG_FORMAT variables,
+// with the `WithEnv`'s prefix, are used when the flags are not set. The selected files must exist,
+// they are searched inside the `WithSearchPaths` as well. An empty "fullpath" without any selected files skips the file.
 func Load(fullpath string, dest interface{}, optional ...Option) error {
 	if !ok(dest) {
 		return ErrBad
 	}
 
-	// default options.
-	opts := options{
-		fileDecoder:   yaml.Unmarshal,
-		disableSurvey: false,
-		flagSet:       nil,
+	opts := newOptions(optional)
+
+	var (
+		files []string
+		// if true then the files were selected, by the command line or the environment, and they must exist.
+		explicit bool
+		// if true then the selected files are decoded by their extension's decoder.
+		byExtension bool
+	)
+
+	if opts.fileDecoder != nil {
+		// the files of the command line or the environment, if any, instead of the "fullpath".
+		var (
+			format string
+			err    error
+		)
+		if files, format, explicit, err = configFiles(fullpath, dest, opts); err != nil {
+			return err
+		}
+
+		// the format, and so the fields' names, is resolved once for all of the sources,
+		// by the selected format or the first selected file's extension.
+		if byExtension = explicit && format == ""; byExtension {
+			format = fileFormat(files[0], opts)
+		}
+
+		if format != "" {
+			WithFormat(format)(&opts)
+		}
 	}
 
-	for _, opt := range optional {
-		opt(&opts)
+	if err := fieldConflicts(reflectType(dest), opts.naming); err != nil {
+		return err
 	}
 
 	// when error is nil:
@@ -133,6 +241,8 @@ func Load(fullpath string, dest interface{}, optional ...Option) error {
 	// we can reduce errors for file not find if survey successfully asked all the required
 	// settings.
 
+	opts.report.recordDefaults(dest, opts.naming)
+
 	if opts.fileDecoder == nil {
 		// file decoder was disabled.
 		// nothing to set, file load disabled and survey has nothing to ask, so pass
@@ -140,64 +250,222 @@ func Load(fullpath string, dest interface{}, optional ...Option) error {
 		return next(dest, nil, opts)
 	}
 
-	// get the abs
-	// which will try to find the 'fullpath' from current workind dir too.
-	f, err := filepath.Abs(fullpath)
-	if err != nil {
-		return next(dest, err, opts)
+	var errs []error
+	for _, file := range files {
+		fileOpts := opts
+		if byExtension {
+			// a file of a different format is decoded by its own decoder,
+			// the fields keep the names of the resolved format.
+			if ext := fileFormat(file, opts); ext != opts.format {
+				fileOpts.fileDecoder, fileOpts.format = decoderFor(ext), ext
+			}
+		}
+
+		// read the raw contents of the file.
+		data, filename, err := readFile(file, fileOpts)
+		if err != nil {
+			fileErr := &FileError{Path: file, Err: err}
+			if explicit && isNotExist(err) {
+				return fileErr // the file was asked for, it's not an optional one.
+			}
+
+			errs = append(errs, fileErr)
+			continue
+		}
+
+		// the next files override the previous ones.
+		if err = decodeFile(data, filename, dest, fileOpts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return next(dest, errors.Join(errs...), opts)
+}
+
+func newOptions(optional []Option) options {
+	// default options.
+	opts := options{
+		fileDecoder:   yaml.Unmarshal,
+		disableSurvey: false,
+		flags:         nil,
+		prompter:      SurveyPrompter{},
+		naming:        defaultNaming,
+		format:        "yaml",
+		provided:      make(presence),
+		maxAttempts:   DefaultMaxAttempts,
 	}
 
-	// read the raw contents of the file.
-	data, err := ioutil.ReadFile(f)
+	for _, opt := range optional {
+		opt(&opts)
+	}
+
+	return opts
+}
+
+func decode(data []byte, filename string, dest interface{}, opts options) error {
+	return next(dest, decodeFile(data, filename, dest, opts), opts)
+}
+
+// decodeFile converts the file's contents to the "dest" configuration, the "filename" may be empty.
+func decodeFile(data []byte, filename string, dest interface{}, opts options) error {
+	data, err := runBeforeDecode(data, opts)
 	if err != nil {
-		return next(dest, err, opts)
+		return err
+	}
+
+	if opts.migrations != nil {
+		if data, err = migrate(data, filename, opts); err != nil {
+			return fileError(filename, err)
+		}
+	}
+
+	if opts.format != "" {
+		warnDeprecated(data, dest, opts)
 	}
 
 	// convert the file's contents to the configuration and keep the error.
-	err = opts.fileDecoder(data, dest)
+	opts.report.record(dest, opts.naming, SourceFile, func() {
+		err = opts.fileDecoder(data, dest)
+	})
+
+	if err != nil {
+		decodeErr := newDecodeError(opts.format, data, err)
+		if filename == "" {
+			return decodeErr
+		}
+
+		return &FileError{Path: filename, Line: decodeErr.Line, Err: decodeErr}
+	}
 
-	return next(dest, err, opts)
+	opts.provided.addFile(data, dest, opts)
+
+	return runHooks("after decode", dest, opts, func(h Hooks) func(interface{}) error { return h.AfterDecode })
+}
+
+// fileError wraps the "err" with the file's path, if any, i.e `LoadReader` has no path.
+func fileError(filename string, err error) error {
+	if filename == "" {
+		return err
+	}
+
+	return &FileError{Path: filename, Err: err}
 }
 
+// next loads the rest of the sources, the "prev" is the file's error, if any.
+// A missing file is reported only when some fields are still missing at the end,
+// the rest of the errors stop the survey and the validation but the sources still run, so all of the conversion errors are reported at once.
 func next(dest interface{}, prev error, opts options) error {
-	if opts.flagSet != nil {
-		if err := TryLoadFlags(opts.flagSet, dest); err != nil {
-			return err
+	var (
+		errs       []error
+		notExisted error
+	)
+
+	if prev != nil {
+		if isNotExist(prev) {
+			notExisted = prev
+		} else {
+			errs = append(errs, prev)
+		}
+	}
+
+	if opts.kv != nil {
+		var err error
+		opts.report.record(dest, opts.naming, SourceKV, func() {
+			err = tryLoadKV(context.Background(), opts.kv, opts.kvPrefix, dest, opts.naming, opts.provided)
+		})
+		if err != nil {
+			errs = append(errs, err)
 		}
 	}
 
-	// try to ask;
-	// if not enabled then it will return the file decoder's error.
-	// if enabled but nothing to ask then it will return the file decoder's error.
+	if opts.flags != nil {
+		var err error
+		opts.report.record(dest, opts.naming, SourceFlag, func() {
+			err = tryLoadFlags(opts.flags, dest, opts.naming, opts.provided)
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if opts.envEnabled {
+		var err error
+		opts.report.record(dest, opts.naming, SourceEnv, func() {
+			err = tryLoadEnv(opts.envPrefix, dest, opts.naming, opts.provided)
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// the secret fields that are still missing after the secret store, they are stored after a successful survey.
+	var missingSecrets []field
+	if opts.secrets != nil {
+		var err error
+		opts.report.record(dest, opts.naming, SourceSecretStore, func() {
+			missingSecrets, err = loadSecrets(opts.secrets, dest, opts.naming, opts.provided)
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if err := runHooks("before prompt", dest, opts, func(h Hooks) func(interface{}) error { return h.BeforePrompt }); err != nil {
+		return err
+	}
+
 	// if enabled and asked, so settings are set-ed, then skip the file decoder's error and return nil.
-	if !opts.disableSurvey {
-		TryAsk(dest)
+	if !opts.disableSurvey && opts.prompter != nil {
+		var err error
+		opts.report.record(dest, opts.naming, SourcePrompt, func() {
+			_, err = ask(dest, opts)
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	return prev
-}
+	if err := runHooks("after prompt", dest, opts, func(h Hooks) func(interface{}) error { return h.AfterPrompt }); err != nil {
+		return err
+	}
+
+	// cross-field checks, after all sources ran.
+	if err := validateAndAsk(dest, opts); err != nil {
+		return err
+	}
 
-func visitMissingFields(dest interface{}, fn func(f field, fValue reflect.Value)) {
-	v := reflect.ValueOf(dest).Elem()
-	typElem := reflect.TypeOf(dest).Elem() // the struct's type.
-	fields := lookupFields(typElem, field{})
-	for _, f := range fields {
-		fieldVal := v.FieldByIndex(f.Index)
-		if f.Required && zerocheck.IsZero(fieldVal) {
-			fn(f, fieldVal)
+	if len(missingSecrets) > 0 && !opts.disableSurvey {
+		if err := storeSecrets(opts.secrets, dest, opts.naming, missingSecrets); err != nil {
+			opts.warnf("%v", err)
 		}
 	}
+
+	if notExisted != nil || opts.required {
+		if missing := missingFields(dest, opts.naming, opts.provided); len(missing) > 0 {
+			return errors.Join(notExisted, &MissingFieldsError{Fields: missing})
+		}
+	}
+
+	return runHooks("after load", dest, opts, func(h Hooks) func(interface{}) error { return h.AfterLoad })
+}
+
+func reflectType(dest interface{}) reflect.Type {
+	return reflect.TypeOf(dest).Elem() // the struct's type.
 }
 
 // check if gotValue is the same as fieldKind, if yes then set it's
 // second, check if it's string, then take that string and try to parse
 // in the fieldKind's value.
-func parseValue(gotValue interface{}, fieldTyp reflect.Type) (value interface{}) {
+func parseValue(gotValue interface{}, f field, fieldTyp reflect.Type) (value interface{}) {
 	switch v := gotValue.(type) {
 	case bool:
 		return parseBool(v, fieldTyp)
 	case string:
-		return parseString(v, fieldTyp)
+		return parseString(v, f, fieldTyp)
 	case int:
 		return parseInt(v, fieldTyp)
 	default:
@@ -205,46 +473,123 @@ func parseValue(gotValue interface{}, fieldTyp reflect.Type) (value interface{})
 	}
 }
 
-// TimeLayout is the layout that is used
-// when a field is a time.Time type and it's required.
-// the user type a time in string, and in order to this
-// string to be converted to a time.Time and be set-ed
-// to the setting field it needs to have a known layout.
-//
-// Defaults to "Mon, 02 Jan 2006 15:04:05 GMT".
-var TimeLayout = "Mon, 02 Jan 2006 15:04:05 GMT"
-
 // parses a string based on the wanted field's type of kind and
 // returns the result value that will be set-ed to the field by the caller.
-func parseString(got string, fieldTyp reflect.Type) (value interface{}) {
-	want := fieldTyp.Kind()
-	switch want {
-	case reflect.String:
-		return got // we had a string and we want a string, just return.
-	case reflect.Int:
-		// we could use dynamic "bits" variable but we don't.
-		value, _ = strconv.Atoi(got)
-	case reflect.Int32:
-		value, _ = strconv.ParseInt(got, 10, 32)
-	case reflect.Int64:
-		value, _ = strconv.ParseInt(got, 10, 64)
-	case reflect.Float32:
-		value, _ = strconv.ParseFloat(got, 32)
-	case reflect.Float64:
-		value, _ = strconv.ParseInt(got, 10, 64)
-	case reflect.Bool:
+// Returns nil if the "got" can't be parsed, see `convertString` for the reason.
+func parseString(got string, f field, fieldTyp reflect.Type) interface{} {
+	value, _ := convertString(got, f, fieldTyp)
+	return value
+}
+
+// convertString parses the "got" to a value of the "fieldTyp" itself,
+// the error describes the expected value, i.e "expected integer between 1 and 65535, got 'abc'".
+// Numbers are checked against the field's "min" and "max" tags too.
+func convertString(got string, f field, fieldTyp reflect.Type) (interface{}, error) {
+	if isSetter(fieldTyp) {
+		ptr := reflect.New(fieldTyp)
+		if err := ptr.Interface().(Setter).Set(got); err != nil {
+			return nil, err
+		}
+
+		return ptr.Elem().Interface(), nil
+	}
+
+	var (
+		value interface{}
+		err   error
+	)
+
+	switch kind := fieldTyp.Kind(); {
+	case fieldTyp == durationType:
+		if value, err = time.ParseDuration(got); err != nil {
+			return nil, fmt.Errorf("expected duration, i.e 30s or 1h30m, got '%s'", maskValue(f, got))
+		}
+	case fieldTyp == timeType:
+		if value, err = parseTime(got, f); err != nil {
+			return nil, err
+		}
+	case kind == reflect.String:
+		value = got // we had a string and we want a string, just return.
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		n, parseErr := strconv.ParseInt(got, 10, fieldTyp.Bits())
+		if parseErr != nil || !inRange(float64(n), f) {
+			return nil, fmt.Errorf("expected integer%s, got '%s'", rangeText(f), maskValue(f, got))
+		}
+		value = n
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		n, parseErr := strconv.ParseUint(got, 10, fieldTyp.Bits())
+		if parseErr != nil || !inRange(float64(n), f) {
+			return nil, fmt.Errorf("expected non-negative integer%s, got '%s'", rangeText(f), maskValue(f, got))
+		}
+		value = n
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		n, parseErr := strconv.ParseFloat(got, fieldTyp.Bits())
+		if parseErr != nil || !inRange(n, f) {
+			return nil, fmt.Errorf("expected number%s, got '%s'", rangeText(f), maskValue(f, got))
+		}
+		value = n
+	case kind == reflect.Bool:
 		// this is already checked before but keep for future, if I decide to remove confirmation dialogs.
-		value, _ = strconv.ParseBool(got)
-	case reflect.Slice:
-		value = strings.Split(got, ",")
-	case reflect.Struct:
-		if fieldTyp.AssignableTo(reflect.TypeOf(time.Time{})) {
-			// if setting is a struct and it's time.
-			value, _ = time.Parse(TimeLayout, got)
+		if value, err = strconv.ParseBool(got); err != nil {
+			return nil, fmt.Errorf("expected true or false, got '%s'", maskValue(f, got))
+		}
+	case kind == reflect.Slice:
+		if value, err = parseList(got, f, fieldTyp); err != nil {
+			return nil, err
+		}
+	case kind == reflect.Map:
+		if value, err = parseMap(got, f, fieldTyp); err != nil {
+			return nil, err
 		}
+	default:
+		return nil, fmt.Errorf("unsupported type %s", fieldTyp)
+	}
+
+	return reflect.ValueOf(value).Convert(fieldTyp).Interface(), nil
+}
+
+// inRange reports whether a number is inside the field's "min" and "max" tags, if any.
+func inRange(n float64, f field) bool {
+	if min, err := strconv.ParseFloat(f.Min, 64); err == nil && n < min {
+		return false
+	}
+
+	if max, err := strconv.ParseFloat(f.Max, 64); err == nil && n > max {
+		return false
+	}
+
+	return true
+}
+
+// rangeText describes the field's "min" and "max" tags, i.e " between 1 and 65535".
+func rangeText(f field) string {
+	switch {
+	case f.Min != "" && f.Max != "":
+		return fmt.Sprintf(" between %s and %s", f.Min, f.Max)
+	case f.Min != "":
+		return " of at least " + f.Min
+	case f.Max != "":
+		return " of at most " + f.Max
+	default:
+		return ""
 	}
+}
+
+// setString parses the "got" to the field's type and sets it,
+// reports whether the field's type is supported and the "got" is valid.
+func setString(fValue reflect.Value, f field, got string) bool {
+	return assignString(fValue, f, got) == nil
+}
 
-	return
+// assignString same as `setString` but it returns the reason of the failure.
+func assignString(fValue reflect.Value, f field, got string) error {
+	value, err := convertString(got, f, fValue.Type())
+	if err != nil {
+		return err
+	}
+
+	fValue.Set(reflect.ValueOf(value))
+	return nil
 }
 
 // parseInt parses an int based on the wanted field's type of kind and
@@ -0,0 +1,109 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+type testDBCredentials struct {
+	Username string `yaml:"Username"`
+	Password string `yaml:"Password" config:"password"`
+	Host     string `yaml:"Host"`
+}
+
+type testConfiguration struct {
+	Addr       string            `yaml:"Addr"`
+	ServerName string            `yaml:"ServerName"`
+	Debug      bool              `yaml:"Debug"`
+	Year       int               `yaml:"Year"`
+	Ignored    string            `yaml:"-" config:"-"`
+	DB         testDBCredentials `yaml:"DB"`
+}
+
+const testConfigFile = `
+Addr: ":8080"
+Year: 2017
+DB:
+  Host: "localhost"
+`
+
+func TestLoad(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": testConfigFile})
+	answers := configtest.Answers(map[string]string{
+		"Debug":       "yes",
+		"DB.Username": "kataras",
+		"DB.Password": "123",
+	})
+
+	c := testConfiguration{ServerName: "iris"}
+	report := configtest.Load(t, "./config.yml", &c,
+		WithFS(fsys),
+		WithFlags(configtest.Flags("-year", "2019")),
+		WithPrompter(answers))
+
+	configtest.AssertLoaded(t, c, testConfiguration{
+		Addr:       ":8080",
+		ServerName: "iris",
+		Debug:      true,
+		Year:       2017,
+		DB: testDBCredentials{
+			Username: "kataras",
+			Password: "123",
+			Host:     "localhost",
+		},
+	})
+
+	configtest.AssertSource(t, report, "ServerName", "default")
+	configtest.AssertSource(t, report, "Addr", "file")
+	configtest.AssertSource(t, report, "DB.Host", "file")
+	configtest.AssertSource(t, report, "DB.Password", "prompt")
+	configtest.AssertSource(t, report, "Ignored", "")
+
+	// the year was already set by the file, so the flag should not override it.
+	configtest.AssertSource(t, report, "Year", "file")
+
+	if expected, got := []string{"Debug", "DB.Username", "DB.Password"}, answers.Asked(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected to be asked for %v but got: %v", expected, got)
+	}
+}
+
+func TestLoadFlags(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": "Addr: :8080"})
+
+	var c testConfiguration
+	report := configtest.Load(t, "config.yml", &c,
+		WithFS(fsys),
+		WithFlags(configtest.Flags("-year", "2019", "-debug", "-db.host=localhost")),
+		WithoutSurvey)
+
+	configtest.AssertLoaded(t, c, testConfiguration{
+		Addr:  ":8080",
+		Debug: true,
+		Year:  2019,
+		DB:    testDBCredentials{Host: "localhost"},
+	})
+
+	configtest.AssertSource(t, report, "Year", "flag")
+	configtest.AssertSource(t, report, "Debug", "flag")
+	configtest.AssertSource(t, report, "DB.Host", "flag")
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	var c testConfiguration
+	err := Load("config.yml", &c, WithFS(configtest.FS(nil)), WithoutSurvey)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a not exist error but got: %v", err)
+	}
+}
+
+func TestLoadBad(t *testing.T) {
+	var c testConfiguration
+	if err := Load("config.yml", c); err != ErrBad {
+		t.Fatalf("expected %v but got: %v", ErrBad, err)
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+)
+
+// The names of the flags which select the configuration files, i.e
+// "-c config.yml -c config.prod.yml" or "--config=config.json --config-format=json".
+// They are declared by `BindFlags`, `BindPFlags` and `BindCommand`
+// and the matching environment variables, i.e APP_CONFIG and APP_CONFIG_FORMAT, are read when `WithEnv` is used.
+//
+// Can be changed to custom ones if needed, before the flags are declared.
+var (
+	ConfigFlag       = "config"
+	ConfigFlagShort  = "c"
+	ConfigFormatFlag = "config-format"
+)
+
+const (
+	configFlagUsage       = "the configuration file, repeat it to overlay more files"
+	configFormatFlagUsage = "the format of the configuration files, i.e yaml, json or toml"
+)
+
+// configFiles returns the configuration files that `Load` should read, in order, and their format, if any.
+// The files of the flags take precedence over the ones of the environment variable
+// and both over the "fullpath", these are "explicit" as they must exist.
+func configFiles(fullpath string, dest interface{}, opts options) (files []string, format string, explicit bool, err error) {
+	if opts.flags != nil && !hasFlag(dest, opts.naming, ConfigFlag) {
+		if !opts.flags.Parsed() {
+			if err = opts.flags.Parse(os.Args[1:]); err != nil {
+				return
+			}
+		}
+
+		if got, ok := opts.flags.Lookup(ConfigFormatFlag, ""); ok {
+			format = got
+		}
+
+		if got, ok := opts.flags.Lookup(ConfigFlag, ""); ok && got != "" {
+			if files, err = splitList(field{Name: ConfigFlag}, got); err != nil {
+				return
+			}
+		}
+	}
+
+	if opts.envEnabled {
+		if got, ok := os.LookupEnv(envName(opts.envPrefix, field{Name: ConfigFormatFlag})); ok && format == "" {
+			format = got
+		}
+
+		if got, ok := os.LookupEnv(envName(opts.envPrefix, field{Name: ConfigFlag})); ok && got != "" && len(files) == 0 {
+			if files, err = splitList(field{Name: ConfigFlag}, got); err != nil {
+				return
+			}
+		}
+	}
+
+	if len(files) > 0 {
+		explicit = true
+		return
+	}
+
+	if fullpath != "" {
+		files = []string{fullpath}
+	}
+
+	return
+}
+
+// hasFlag reports whether a field of the "dest" uses the flag's name, or shorthand, already.
+func hasFlag(dest interface{}, n naming, name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, f := range lookupFields(reflectType(dest), field{}, n) {
+		if f.Required && (n.flagName(f) == name || f.Short == name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fileFormat returns the format of an explicit configuration file by its extension,
+// i.e "json" for "config.json", if it's a registered one and the file decoder is not a custom one.
+func fileFormat(filename string, opts options) string {
+	if opts.format == "" {
+		return ""
+	}
+
+	if ext := normalizeFormat(filepath.Ext(filename)); ext != "" {
+		if _, ok := decoders[ext]; ok {
+			return ext
+		}
+	}
+
+	return opts.format
+}
+
+// bindConfigFlags declares the `ConfigFlag`, with the "short" shorthand if not empty,
+// and the `ConfigFormatFlag`, if they are not declared already.
+func bindConfigFlags(set *flag.FlagSet, short string) {
+	if set.Lookup(ConfigFlag) == nil {
+		files := new(listFlag) // shared by the name and the shorthand.
+		set.Var(files, ConfigFlag, configFlagUsage)
+		if short != "" && set.Lookup(short) == nil {
+			set.Var(files, short, configFlagUsage)
+		}
+	}
+
+	if set.Lookup(ConfigFormatFlag) == nil {
+		set.String(ConfigFormatFlag, "", configFormatFlagUsage)
+	}
+}
+
+// bindConfigPFlags same as `bindConfigFlags` but for a spf13/pflag flag set.
+func bindConfigPFlags(set *pflag.FlagSet, short string) {
+	if set.Lookup(ConfigFlag) == nil {
+		if short != "" && set.ShorthandLookup(short) != nil {
+			short = ""
+		}
+		set.VarP(new(listFlag), ConfigFlag, short, configFlagUsage)
+	}
+
+	if set.Lookup(ConfigFormatFlag) == nil {
+		set.String(ConfigFormatFlag, "", configFormatFlagUsage)
+	}
+}
@@ -0,0 +1,138 @@
+package config_test
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+
+	"github.com/spf13/pflag"
+)
+
+var testConfigFiles = configtest.FS(map[string]string{
+	"config.yml":               "Addr: \":8080\"\nYear: 2017\n",
+	"config.prod.yml":          "Addr: \":443\"\n",
+	"config.json":              `{"Addr": ":80", "DB": {"Host": "localhost"}}`,
+	"etc/app/config.local.yml": "Year: 2019\n",
+})
+
+func TestLoadConfigFlag(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+
+	var c testConfiguration
+	if err := BindFlags(set, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := set.Parse([]string{"-config", "config.yml", "-c", "config.prod.yml,config.local.yml"}); err != nil {
+		t.Fatal(err)
+	}
+
+	report := configtest.Load(t, "missing.yml", &c, WithFS(testConfigFiles), WithSearchPaths(".", "etc/app"), WithFlags(set), WithoutSurvey)
+	configtest.AssertLoaded(t, c, testConfiguration{Addr: ":443", Year: 2019})
+	configtest.AssertSource(t, report, "Addr", "file")
+}
+
+func TestLoadConfigFlagFormat(t *testing.T) {
+	set := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	var c testConfiguration
+	if err := BindPFlags(set, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// the format of the extension.
+	if err := set.Parse([]string{"-c", "config.json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	configtest.Load(t, "config.yml", &c, WithFS(testConfigFiles), WithFlagSource(PFlags(set)), WithoutSurvey)
+	configtest.AssertLoaded(t, c, testConfiguration{Addr: ":80", DB: testDBCredentials{Host: "localhost"}})
+
+	set = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c = testConfiguration{}
+	if err := BindPFlags(set, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := set.Parse([]string{"--config", "config.yml", "--config-format", "json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var decodeErr *DecodeError
+	if err := Load("", &c, WithFS(testConfigFiles), WithFlagSource(PFlags(set)), WithoutSurvey); !errors.As(err, &decodeErr) || decodeErr.Format != "json" {
+		t.Fatalf("expected a json decode error but got: %v", err)
+	}
+}
+
+func TestLoadConfigFlagShorthandConflict(t *testing.T) {
+	type shortConfiguration struct {
+		Addr        string `yaml:"Addr"`
+		Concurrency int    `yaml:"Concurrency" short:"c"`
+	}
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+
+	var c shortConfiguration
+	if err := BindFlags(set, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := set.Parse([]string{"-config", "config.prod.yml", "-c", "4"}); err != nil {
+		t.Fatal(err)
+	}
+
+	configtest.Load(t, "", &c, WithFS(testConfigFiles), WithFlags(set), WithoutSurvey)
+	configtest.AssertLoaded(t, c, shortConfiguration{Addr: ":443", Concurrency: 4})
+
+	pset := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c = shortConfiguration{}
+	if err := BindPFlags(pset, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pset.Parse([]string{"--config", "config.prod.yml", "-c", "8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	configtest.Load(t, "", &c, WithFS(testConfigFiles), WithFlagSource(PFlags(pset)), WithoutSurvey)
+	configtest.AssertLoaded(t, c, shortConfiguration{Addr: ":443", Concurrency: 8})
+}
+
+func TestLoadConfigEnv(t *testing.T) {
+	t.Setenv("APP_CONFIG", "config.yml,config.prod.yml")
+
+	var c testConfiguration
+	configtest.Load(t, "", &c, WithFS(testConfigFiles), WithEnv("APP"), WithoutSurvey)
+	configtest.AssertLoaded(t, c, testConfiguration{Addr: ":443", Year: 2017})
+
+	t.Setenv("APP_CONFIG", "missing.yml")
+	c = testConfiguration{}
+	err := Load("config.yml", &c, WithFS(testConfigFiles), WithEnv("APP"), WithoutSurvey)
+
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Path != "missing.yml" || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a not exist error of the selected file but got: %v", err)
+	}
+}
+
+func TestLoadConfigFlagNaming(t *testing.T) {
+	type jsonConfiguration struct {
+		Addr     string `json:"addr"`
+		MaxConns int    `json:"max_conns"`
+	}
+
+	fsys := configtest.FS(map[string]string{"app.json": `{"addr": ":80"}`})
+
+	// the names of the selected file's format are used by the rest of the sources too.
+	var c jsonConfiguration
+	report := configtest.Load(t, "config.yml", &c, WithFS(fsys), WithFlags(configtest.Flags("-config", "app.json", "-max_conns", "5")), WithoutSurvey)
+	configtest.AssertLoaded(t, c, jsonConfiguration{Addr: ":80", MaxConns: 5})
+	configtest.AssertSource(t, report, "max_conns", "flag")
+}
@@ -0,0 +1,134 @@
+// Package configtest provides helpers to test the configuration loading
+// of the config package without touching the real file system, command line or terminal.
+package configtest
+
+import (
+	"flag"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kataras/pkg/config"
+)
+
+// FS returns a new in-memory file system with the "files",
+// the map's keys are the files' names and the values are their contents.
+// Pass it to the `config.WithFS` option.
+func FS(files map[string]string) fs.FS {
+	fsys := make(fstest.MapFS, len(files))
+	for name, contents := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(contents), Mode: 0644}
+	}
+
+	return fsys
+}
+
+// Flags returns a new, parsed, flag set based on the "args", i.e "-addr", ":8080", "-debug".
+// A flag followed by a value or written as "-name=value" is declared as a string flag
+// and a flag without a value is declared as a boolean one.
+//
+// It panics if the "args" can't be parsed.
+func Flags(args ...string) *flag.FlagSet {
+	set := flag.NewFlagSet("configtest", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			continue
+		}
+
+		name := strings.TrimLeft(args[i], "-")
+		if idx := strings.IndexByte(name, '='); idx != -1 {
+			set.String(name[:idx], "", "")
+			continue
+		}
+
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			set.String(name, "", "")
+			i++
+			continue
+		}
+
+		set.Bool(name, false, "")
+	}
+
+	if err := set.Parse(args); err != nil {
+		panic(err)
+	}
+
+	return set
+}
+
+// Answers returns a new scripted `config.Prompter` which answers from the "answers",
+// the map's keys are the fields' names, i.e "DBCredentials.Password".
+// Pass it to the `config.WithPrompter` option.
+func Answers(answers map[string]string) *config.ScriptedPrompter {
+	return config.NewScriptedPrompter(answers)
+}
+
+// Load calls the `config.Load` with the "opts" and returns its report.
+// By default questions are answered by an empty scripted prompter,
+// so nothing is read from the terminal, use the `Answers` to provide some.
+//
+// The test fails immediately if `config.Load` returned an error.
+func Load(t testing.TB, filename string, dest interface{}, opts ...config.Option) *config.Report {
+	t.Helper()
+
+	report, err := load(opts, func(opts []config.Option) error {
+		return config.Load(filename, dest, opts...)
+	})
+	if err != nil {
+		t.Fatalf("load %s: %v", filename, err)
+	}
+
+	return report
+}
+
+// LoadReader same as `Load` but it calls the `config.LoadReader` instead.
+func LoadReader(t testing.TB, r io.Reader, format string, dest interface{}, opts ...config.Option) *config.Report {
+	t.Helper()
+
+	report, err := load(opts, func(opts []config.Option) error {
+		return config.LoadReader(r, format, dest, opts...)
+	})
+	if err != nil {
+		t.Fatalf("load %s: %v", format, err)
+	}
+
+	return report
+}
+
+func load(opts []config.Option, loader func([]config.Option) error) (*config.Report, error) {
+	report := new(config.Report)
+	opts = append([]config.Option{config.WithPrompter(Answers(nil))}, opts...)
+	opts = append(opts, config.WithReport(report))
+
+	return report, loader(opts)
+}
+
+// AssertLoaded fails the test if the "dest" is not deeply equal to the "want".
+// Both can be either struct values or pointers to them.
+func AssertLoaded(t testing.TB, dest, want interface{}) {
+	t.Helper()
+
+	got := reflect.Indirect(reflect.ValueOf(dest)).Interface()
+	expected := reflect.Indirect(reflect.ValueOf(want)).Interface()
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("configuration mismatch:\nexpected: %#+v\nbut got:  %#+v", expected, got)
+	}
+}
+
+// AssertSource fails the test if the field's source of the "report"
+// is not the "want" one, i.e AssertSource(t, report, "Addr", "flag").
+func AssertSource(t testing.TB, report *config.Report, name string, want config.Source) {
+	t.Helper()
+
+	if got := report.Source(name); got != want {
+		t.Fatalf("field '%s' should be set by %q but got: %q", name, want, got)
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Convert reads a configuration's contents of the "fromFormat" from "in" and writes them
+// in the "toFormat" to "out", i.e "yaml" to "json", through a generic tree, so all the keys are kept, even the unknown ones.
+// The "env" format is flat, the nested keys are joined by underscores, i.e DB_HOST.
+//
+// See `ConvertStruct`, `RegisterDecoder` and `RegisterEncoder` too.
+func Convert(in io.Reader, fromFormat string, out io.Writer, toFormat string) error {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	raw, err := decodeRaw(data, fromFormat)
+	if err != nil {
+		return err
+	}
+
+	return encodeTo(out, raw, toFormat)
+}
+
+// ConvertStruct same as `Convert` but it round-trips through the "dest" configuration,
+// a pointer to a struct value which may hold the defaults, so the keys are renamed by the formats' tags,
+// i.e `yaml:"max_conns" json:"maxConns"`, the values are converted to the fields' types and the unknown keys are dropped.
+func ConvertStruct(in io.Reader, fromFormat string, out io.Writer, toFormat string, dest interface{}) error {
+	if !ok(dest) {
+		return ErrBad
+	}
+
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	if err = decoderFor(fromFormat)(data, dest); err != nil {
+		return err
+	}
+
+	return encodeTo(out, dest, toFormat)
+}
+
+func encodeTo(out io.Writer, v interface{}, format string) error {
+	marshal, ok := encoders[normalizeFormat(format)]
+	if !ok {
+		return fmt.Errorf("unknown configuration format: %q", format)
+	}
+
+	b, err := marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(b)
+	return err
+}
@@ -0,0 +1,115 @@
+package config_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+const testConvertYAML = `addr: :8080
+max_conns: 10
+peers:
+- a
+- b
+db:
+  host: localhost
+  password: "123 456"
+`
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"json", `{
+  "addr": ":8080",
+  "db": {
+    "host": "localhost",
+    "password": "123 456"
+  },
+  "max_conns": 10,
+  "peers": [
+    "a",
+    "b"
+  ]
+}
+`},
+		{"toml", `addr = ":8080"
+max_conns = 10
+peers = ["a", "b"]
+
+[db]
+  host = "localhost"
+  password = "123 456"
+`},
+		{"env", `ADDR=:8080
+DB_HOST=localhost
+DB_PASSWORD="123 456"
+MAX_CONNS=10
+PEERS=a,b
+`},
+	}
+
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		if err := Convert(strings.NewReader(testConvertYAML), "yaml", buf, tt.format); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); tt.expected != got {
+			t.Fatalf("[%s] expected:\n%s\nbut got:\n%s", tt.format, tt.expected, got)
+		}
+
+		// and back.
+		back := new(bytes.Buffer)
+		if err := Convert(strings.NewReader(buf.String()), tt.format, back, "yaml"); err != nil {
+			t.Fatalf("[%s] %v", tt.format, err)
+		}
+	}
+}
+
+type testConvertConfiguration struct {
+	Addr     string        `yaml:"addr" json:"addr" toml:"addr"`
+	MaxConns int           `yaml:"max_conns" json:"maxConns" toml:"max-conns"`
+	Timeout  time.Duration `yaml:"timeout" json:"timeout" toml:"timeout"`
+	Peers    []string      `yaml:"peers" json:"peers" toml:"peers"`
+}
+
+func TestConvertStruct(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var c testConvertConfiguration
+	if err := ConvertStruct(strings.NewReader(testConvertYAML+"timeout: 30s\nunknown: true\n"), "yaml", buf, "json", &c); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "addr": ":8080",
+  "maxConns": 10,
+  "timeout": 30000000000,
+  "peers": [
+    "a",
+    "b"
+  ]
+}
+`
+	if got := buf.String(); expected != got {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestLoadEnvFormat(t *testing.T) {
+	contents := `# comment
+export ADDR=":9090"
+MAX_CONNS=20 # trailing comment
+TIMEOUT=1m
+PEERS='a,b'
+`
+
+	var c testConvertConfiguration
+	configtest.LoadReader(t, strings.NewReader(contents), "env", &c, WithoutSurvey)
+	configtest.AssertLoaded(t, c, testConvertConfiguration{Addr: ":9090", MaxConns: 20, Timeout: time.Minute, Peers: []string{"a", "b"}})
+}
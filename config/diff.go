@@ -0,0 +1,159 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// ChangeKind is the kind of a `Change`.
+type ChangeKind string
+
+// The available kinds of a `Change`.
+const (
+	// ChangeAdded is reported when a field, with a non-zero value, exists only on the new configuration,
+	// i.e a new element of a slice.
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved is reported when a field, with a non-zero value, exists only on the old configuration.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeModified is reported when a field exists on both configurations with different values,
+	// a change from or to a zero value included.
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change describes the difference of a single field between two configurations.
+// The values of the secret fields are replaced with "******".
+type Change struct {
+	// Path is the field's full name, i.e "DBCredentials.Host".
+	Path string      `json:"path"`
+	Kind ChangeKind  `json:"kind"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// String returns a single-line text representation of the change,
+// i.e `~ Addr: ":8080" -> ":9090"`.
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatChangeValue(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, formatChangeValue(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, formatChangeValue(c.Old), formatChangeValue(c.New))
+	}
+}
+
+func formatChangeValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+// redacted replaces the values of the secret fields.
+const redacted = "******"
+
+// Diff walks the fields of the "a" and "b" configurations, which should be structs or pointers to structs,
+// and returns their differences, in the order of the fields. Fields are matched by their full names,
+// so the two configurations can be of different types as well.
+// The ignored and unexported fields are not compared.
+//
+// See `WriteChanges` too.
+func Diff(a, b interface{}) []Change {
+	oldValues, oldNames := diffValues(a)
+	newValues, newNames := diffValues(b)
+
+	var changes []Change
+
+	for _, name := range oldNames {
+		oldVal := oldValues[name]
+		newVal, ok := newValues[name]
+		if !ok {
+			// the field does not exist on the new configuration.
+			if !oldVal.zero {
+				changes = append(changes, Change{Path: name, Kind: ChangeRemoved, Old: oldVal.value})
+			}
+			continue
+		}
+
+		if !(oldVal.zero && newVal.zero) && !reflect.DeepEqual(oldVal.raw, newVal.raw) {
+			changes = append(changes, Change{Path: name, Kind: ChangeModified, Old: oldVal.value, New: newVal.value})
+		}
+	}
+
+	for _, name := range newNames {
+		if _, ok := oldValues[name]; ok {
+			continue // already compared.
+		}
+
+		if newVal := newValues[name]; !newVal.zero {
+			changes = append(changes, Change{Path: name, Kind: ChangeAdded, New: newVal.value})
+		}
+	}
+
+	return changes
+}
+
+type diffValue struct {
+	raw   interface{}
+	value interface{} // redacted if secret, the fingerprint if sensitive.
+	zero  bool
+}
+
+func diffValues(cfg interface{}) (map[string]diffValue, []string) {
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	values := make(map[string]diffValue)
+	var names []string
+
+	walkFields(v, "", defaultNaming, func(f field, fieldVal reflect.Value) {
+		// ignored or unexported, the slices and maps of sections are compared by their elements' fields.
+		if !f.Required || !fieldVal.CanInterface() || isSectionList(fieldVal.Type()) {
+			return
+		}
+
+		raw := fieldVal.Interface()
+		value := raw
+		if f.Secret {
+			value = redacted
+		} else if f.Sensitive {
+			value = Fingerprint(formatValue(f, fieldVal))
+		}
+
+		values[f.Name] = diffValue{raw: raw, value: value, zero: isZero(fieldVal)}
+		names = append(names, f.Name)
+	})
+
+	return values, names
+}
+
+// WriteChanges writes the "changes" to "w" based on the "format",
+// "text" writes one change per line and "json" writes a JSON array of them.
+func WriteChanges(w io.Writer, changes []Change, format string) error {
+	switch format {
+	case "", "text":
+		for _, c := range changes {
+			if _, err := fmt.Fprintln(w, c.String()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case "json":
+		if changes == nil {
+			changes = []Change{}
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	default:
+		return fmt.Errorf("unknown changes format: %q", format)
+	}
+}
@@ -0,0 +1,68 @@
+package config_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+)
+
+func TestDiff(t *testing.T) {
+	a := testConfiguration{
+		Addr:  ":8080",
+		Debug: true,
+		DB:    testDBCredentials{Password: "old"},
+	}
+
+	b := testConfiguration{
+		Addr: ":9090",
+		Year: 2019,
+		DB:   testDBCredentials{Password: "new"},
+	}
+
+	expected := []Change{
+		{Path: "Addr", Kind: ChangeModified, Old: ":8080", New: ":9090"},
+		{Path: "Debug", Kind: ChangeModified, Old: true, New: false},
+		{Path: "Year", Kind: ChangeModified, Old: 0, New: 2019},
+		{Path: "DB.Password", Kind: ChangeModified, Old: "******", New: "******"},
+	}
+
+	changes := Diff(a, &b)
+	if !reflect.DeepEqual(expected, changes) {
+		t.Fatalf("expected changes:\n%v\nbut got:\n%v", expected, changes)
+	}
+
+	if changes := Diff(a, a); len(changes) > 0 {
+		t.Fatalf("expected no changes but got: %v", changes)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WriteChanges(buf, changes[:3], "text"); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "~ Addr: \":8080\" -> \":9090\"\n~ Debug: true -> false\n~ Year: 0 -> 2019\n", buf.String(); expected != got {
+		t.Fatalf("expected text:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	// the fields that exist on one of the configurations only.
+	type oldConfiguration struct {
+		Addr  string
+		Debug bool
+	}
+	type newConfiguration struct {
+		Addr string
+		Year int
+	}
+
+	expected = []Change{
+		{Path: "Debug", Kind: ChangeRemoved, Old: true},
+		{Path: "Year", Kind: ChangeAdded, New: 2019},
+	}
+
+	changes = Diff(oldConfiguration{Addr: ":8080", Debug: true}, newConfiguration{Addr: ":8080", Year: 2019})
+	if !reflect.DeepEqual(expected, changes) {
+		t.Fatalf("expected changes:\n%v\nbut got:\n%v", expected, changes)
+	}
+}
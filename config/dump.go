@@ -0,0 +1,242 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Redacted returns a safe-to-log copy of the "dest" configuration,
+// the values of its secret fields, tagged as 'config:"password"' or 'config:"secret"',
+// are replaced with "******" and the values of its sensitive fields, tagged as 'config:"sensitive"',
+// are replaced with their fingerprint, see `Fingerprint`. Both are zero if they are not strings, `Dump` writes their masked values though.
+// The "dest" can be a struct or a pointer to a struct value,
+// a pointer returns a pointer to the copy.
+func Redacted(dest interface{}) interface{} {
+	v := reflect.ValueOf(dest)
+	isPtr := v.Kind() == reflect.Ptr
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return dest
+	}
+
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+
+	walkFields(c.Elem(), "", defaultNaming, func(f field, fieldVal reflect.Value) {
+		if !fieldVal.CanSet() || isZero(fieldVal) {
+			return
+		}
+
+		if isSectionList(fieldVal.Type()) {
+			// the elements are shared with the "dest", redact a copy of them instead.
+			fieldVal.Set(copyElements(fieldVal))
+			return
+		}
+
+		if !f.Secret && !f.Sensitive {
+			return
+		}
+
+		if fieldVal.Kind() == reflect.String {
+			fieldVal.SetString(maskValue(f, fieldVal.String()))
+		} else {
+			fieldVal.Set(reflect.Zero(fieldVal.Type()))
+		}
+	})
+
+	if isPtr {
+		return c.Interface()
+	}
+
+	return c.Elem().Interface()
+}
+
+// Fingerprint returns the first 12 hex characters of the SHA-256 sum of a value, prefixed by "sha256:",
+// i.e "sha256:5994471abb01". It's the printed value of the sensitive fields, tagged as 'config:"sensitive"',
+// so two deployments can be checked for the same value without revealing it.
+func Fingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// maskValue returns the printable text of a field's value,
+// it's redacted if the field is secret or its fingerprint if it's sensitive.
+func maskValue(f field, value string) string {
+	switch {
+	case f.Secret:
+		return redacted
+	case f.Sensitive:
+		return Fingerprint(value)
+	default:
+		return value
+	}
+}
+
+// Dump writes the "dest" configuration to "w", its secret and sensitive fields are redacted, see `Redacted`.
+// The "format" can be "yaml", "json", "toml", "env" which writes one NAME=value line per field
+// or "flags" which writes one -name=value line per field, as they are expected by `TryLoadFlags`.
+// Any non-struct field with a zero value is omitted from the "env" and "flags" formats.
+//
+// The secret and sensitive fields of any type are masked, i.e a sensitive `Port int` is written as its fingerprint.
+// When there are such non-string fields the file formats are written from a generic tree, so their keys are sorted.
+func Dump(w io.Writer, dest interface{}, format string) error {
+	if v := reflect.Indirect(reflect.ValueOf(dest)); v.Kind() != reflect.Struct {
+		return ErrBad
+	}
+
+	switch format = normalizeFormat(format); format {
+	case "env":
+		return dumpLines(w, dest, func(f field, value string) string {
+			return envName("", f) + "=" + quoteIfNeeded(value, strconv.Quote)
+		})
+	case "flags":
+		return dumpLines(w, dest, func(f field, value string) string {
+			return "-" + defaultNaming.flagName(f) + "=" + quoteIfNeeded(value, shellQuote)
+		})
+	default:
+		marshal, ok := encoders[format]
+		if !ok {
+			return fmt.Errorf("unknown configuration format: %q", format)
+		}
+
+		value := Redacted(dest)
+		if v := reflect.Indirect(reflect.ValueOf(dest)); hasMaskedValues(v) {
+			// the non-string fields can't hold their masked values, the file's tree can.
+			tree, err := maskedTree(v, format, func(f field, fieldVal reflect.Value) (interface{}, bool) {
+				return maskValue(f, formatValue(f, fieldVal)), !isZero(fieldVal)
+			})
+			if err != nil {
+				return err
+			}
+			value = tree
+		}
+
+		b, err := marshal(value)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(b)
+		return err
+	}
+}
+
+// hasMaskedValues reports whether the struct value "v" has secret or sensitive fields which are not strings and not zero,
+// including the fields of the struct elements of its slices and maps.
+func hasMaskedValues(v reflect.Value) (found bool) {
+	walkFields(v, "", defaultNaming, func(f field, fieldVal reflect.Value) {
+		found = found || (f.Secret || f.Sensitive) && fieldVal.Kind() != reflect.String && !isSectionList(fieldVal.Type()) && !isZero(fieldVal)
+	})
+
+	return
+}
+
+// maskedTree encodes the "v", a struct or a slice or map of sections, to the "format" and returns the generic tree of the encoded contents,
+// where the values of the secret and sensitive fields, of any type, are replaced by the "mask" ones, if it reports true.
+func maskedTree(v reflect.Value, format string, mask func(f field, fieldVal reflect.Value) (interface{}, bool)) (interface{}, error) {
+	marshal, ok := encoders[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown configuration format: %q", format)
+	}
+
+	b, err := marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	maskField := func(f field, fieldVal reflect.Value, _ interface{}, set func(interface{})) {
+		if f.Secret || f.Sensitive {
+			if value, ok := mask(f, fieldVal); ok {
+				set(value)
+			}
+		}
+	}
+
+	if v.Kind() == reflect.Struct {
+		raw, err := decodeRaw(b, format)
+		if err != nil {
+			return nil, err
+		}
+
+		walkRawFields(raw, v, "", defaultNaming, fileNaming(format), maskField)
+		return raw, nil
+	}
+
+	fileDecoder, ok := decoders[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown configuration format: %q", format)
+	}
+
+	var tree interface{}
+	if err = fileDecoder(b, &tree); err != nil {
+		return nil, err
+	}
+
+	tree = normalizeRaw(tree)
+	walkRawElements(tree, v, "", defaultNaming, fileNaming(format), maskField)
+	return tree, nil
+}
+
+// copyElements returns a shallow copy of a slice or a map.
+func copyElements(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Slice {
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c
+	}
+
+	c := reflect.MakeMapWithSize(v.Type(), v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		c.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	return c
+}
+
+// dumpLines writes a line for each non-zero field of the "dest",
+// the values of the secret and sensitive fields of any type are masked, see `maskValue`.
+func dumpLines(w io.Writer, dest interface{}, line func(f field, value string) string) (err error) {
+	walkFields(reflect.Indirect(reflect.ValueOf(dest)), "", defaultNaming, func(f field, fieldVal reflect.Value) {
+		if err != nil || !f.Required || isSectionList(fieldVal.Type()) || isZero(fieldVal) {
+			return
+		}
+
+		_, err = fmt.Fprintln(w, line(f, maskValue(f, formatValue(f, fieldVal))))
+	})
+
+	return
+}
+
+// formatValue returns the text representation of a field's value,
+// which can be parsed back to the field's type.
+func formatValue(f field, v reflect.Value) string {
+	if isCollection(v.Type()) {
+		return formatList(f, v)
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return formatTime(value, f)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func quoteIfNeeded(s string, quote func(string) string) string {
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"'`$\\#;&|<>(){}*?!~") {
+		return quote(s)
+	}
+
+	return s
+}
+
+// shellQuote quotes a string for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
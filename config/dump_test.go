@@ -0,0 +1,117 @@
+package config_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+func TestRedacted(t *testing.T) {
+	c := testConfiguration{Addr: ":8080", DB: testDBCredentials{Username: "kataras", Password: "123"}}
+
+	safe := Redacted(&c).(*testConfiguration)
+	if expected, got := "******", safe.DB.Password; expected != got {
+		t.Fatalf("expected password to be redacted to %s but got: %s", expected, got)
+	}
+
+	if expected, got := "123", c.DB.Password; expected != got {
+		t.Fatalf("expected the original password to be kept as %s but got: %s", expected, got)
+	}
+
+	if expected, got := "kataras", Redacted(c).(testConfiguration).DB.Username; expected != got {
+		t.Fatalf("expected username to be %s but got: %s", expected, got)
+	}
+}
+
+func TestDump(t *testing.T) {
+	c := testConfiguration{Addr: ":8080", ServerName: "my server", Year: 2019, DB: testDBCredentials{Password: "123"}}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"env", "ADDR=:8080\nSERVERNAME=\"my server\"\nYEAR=2019\nDB_PASSWORD=\"******\"\n"},
+		{"flags", "-addr=:8080\n-servername='my server'\n-year=2019\n-db.password='******'\n"},
+		{"json", "{\n  \"Addr\": \":8080\",\n  \"ServerName\": \"my server\",\n  \"Debug\": false,\n  \"Year\": 2019,\n  \"Ignored\": \"\",\n  \"DB\": {\n    \"Username\": \"\",\n    \"Password\": \"******\",\n    \"Host\": \"\"\n  }\n}\n"},
+	}
+
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		if err := Dump(buf, c, tt.format); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); tt.expected != got {
+			t.Fatalf("[%s] expected:\n%s\nbut got:\n%s", tt.format, tt.expected, got)
+		}
+	}
+}
+
+type testSensitiveConfiguration struct {
+	APIKey   string `config:"sensitive"`
+	Password string `config:"secret"`
+	Port     int    `config:"sensitive"`
+}
+
+func TestSensitive(t *testing.T) {
+	a := testSensitiveConfiguration{APIKey: "123456", Password: "pass", Port: 8080}
+
+	if expected, got := "sha256:8d969eef6eca", Fingerprint("123456"); expected != got {
+		t.Fatalf("expected fingerprint: %s but got: %s", expected, got)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Dump(buf, a, "env"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the sensitive fields of any type are printed as their fingerprint.
+	if expected, got := "APIKEY=sha256:8d969eef6eca\nPASSWORD=\"******\"\nPORT="+Fingerprint("8080")+"\n", buf.String(); expected != got {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	// the yaml and json files too.
+	for format, expected := range map[string]string{
+		"yaml": "apikey: sha256:8d969eef6eca\npassword: '******'\nport: " + Fingerprint("8080") + "\n",
+		"json": "{\n  \"APIKey\": \"sha256:8d969eef6eca\",\n  \"Password\": \"******\",\n  \"Port\": \"" + Fingerprint("8080") + "\"\n}\n",
+	} {
+		buf.Reset()
+		if err := Dump(buf, a, format); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); expected != got {
+			t.Fatalf("[%s] expected:\n%s\nbut got:\n%s", format, expected, got)
+		}
+	}
+
+	b := a
+	b.APIKey, b.Password = "654321", "word"
+	changes := Diff(a, b)
+	if expected, got := 2, len(changes); expected != got {
+		t.Fatalf("expected %d changes but got: %v", expected, changes)
+	}
+
+	if expected, got := `~ APIKey: "sha256:8d969eef6eca" -> "`+Fingerprint("654321")+`"`, changes[0].String(); expected != got {
+		t.Fatalf("expected change: %s but got: %s", expected, got)
+	}
+
+	var c testSensitiveConfiguration
+	err := Load("", &c, WithFileDecoder(nil), WithFlags(configtest.Flags("-port", "secret-port")), WithoutSurvey)
+	if err == nil || strings.Contains(err.Error(), "secret-port") || !strings.Contains(err.Error(), Fingerprint("secret-port")) {
+		t.Fatalf("expected the error to contain the value's fingerprint only but got: %v", err)
+	}
+
+	// the invalid lists are masked too.
+	var l struct {
+		Tokens []string `config:"sensitive"`
+	}
+	t.Setenv("APP_TOKENS", `"secret-token`)
+	err = Load("", &l, WithFileDecoder(nil), WithEnv("APP"), WithoutSurvey)
+	if err == nil || strings.Contains(err.Error(), "secret-token") || !strings.Contains(err.Error(), Fingerprint(`"secret-token`)) {
+		t.Fatalf("expected the list error to contain the value's fingerprint only but got: %v", err)
+	}
+}
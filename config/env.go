@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithEnv enables the config to be loaded from the environment variables,
+// their names are the fields' names, uppercased, with the "prefix", i.e
+// "APP_DBCREDENTIALS_HOST" for the "DBCredentials.Host" field and "APP" prefix.
+// The "." and "-" characters are replaced with underscores.
+// Slices can be set by a comma separated list, i.e APP_PEERS="a,b", or by indexed variables,
+// i.e APP_PEERS_0="a" and APP_PEERS_1="b", which can target the fields of struct elements too, i.e APP_PEERS_0_HOST.
+// Maps are set by a list of key=value entries, i.e APP_LABELS="env=prod,team=core".
+// The fields of the existing struct elements of slices and maps are named by their index and key,
+// i.e APP_NODES_0_HOST for the "Nodes[0].Host" field and APP_BACKENDS_API_URL for the "Backends.api.URL" one.
+//
+// It scans the environment variables after the flags and before the survey,
+// a field that is already set is not overridden.
+func WithEnv(prefix string) Option {
+	return func(o *options) {
+		o.envEnabled = true
+		o.envPrefix = prefix
+	}
+}
+
+// TryLoadEnv tries to load the "dest" configuration's missing fields from the environment variables,
+// see `WithEnv` for their names.
+// Returns a `*ConversionError`, or a join of them, for the values that can't be converted to their fields' types.
+func TryLoadEnv(prefix string, dest interface{}) error {
+	if !ok(dest) {
+		return ErrBad
+	}
+
+	return tryLoadEnv(prefix, dest, defaultNaming, nil)
+}
+
+func tryLoadEnv(prefix string, dest interface{}, n naming, provided presence) error {
+	var errs []error
+	visitMissingFields(dest, n, provided, func(f field, fValue reflect.Value) {
+		if got, ok := os.LookupEnv(envName(prefix, f)); ok {
+			if err := assignString(fValue, f, got); err != nil {
+				errs = append(errs, &ConversionError{Field: f.Name, Source: SourceEnv, Key: envName(prefix, f), Err: err})
+				return
+			}
+
+			provided.add(f.Name)
+			return
+		}
+
+		if fValue.Kind() == reflect.Slice {
+			found, err := loadIndexedEnv(os.LookupEnv, prefix, f, fValue, n)
+			if err != nil {
+				errs = append(errs, err)
+			} else if found {
+				provided.add(f.Name)
+			}
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// loadIndexedEnv sets a slice field from its indexed environment variables, starting from zero,
+// i.e APP_PEERS_0 and APP_PEERS_1 for a []string or APP_PEERS_0_HOST and APP_PEERS_0_PORT for a slice of structs.
+// The "lookup" returns the value of a variable, i.e the os.LookupEnv.
+// Reports whether any element was found, the indexes should be contiguous.
+func loadIndexedEnv(lookup func(name string) (string, bool), prefix string, f field, fValue reflect.Value, n naming) (bool, error) {
+	elemTyp := fValue.Type().Elem()
+
+	var subfields []field
+	if isSection(elemTyp) {
+		subfields = lookupFields(elemTyp, field{}, n)
+	}
+
+	slice := reflect.MakeSlice(fValue.Type(), 0, 0)
+	for i := 0; ; i++ {
+		elemPrefix := envName(prefix, f) + "_" + strconv.Itoa(i)
+		elem := reflect.New(elemTyp).Elem()
+
+		found := false
+		if len(subfields) == 0 {
+			if got, ok := lookup(elemPrefix); ok {
+				if err := assignString(elem, f, got); err != nil {
+					return false, &ConversionError{Field: f.Name, Source: SourceEnv, Key: elemPrefix, Err: err}
+				}
+				found = true
+			}
+		}
+
+		for _, sub := range subfields {
+			if got, ok := lookup(envName(elemPrefix, sub)); ok {
+				if err := assignString(elem.FieldByIndex(sub.Index), sub, got); err != nil {
+					return false, &ConversionError{Field: f.Name, Source: SourceEnv, Key: envName(elemPrefix, sub), Err: err}
+				}
+				found = true
+			}
+		}
+
+		if !found {
+			break
+		}
+
+		slice = reflect.Append(slice, elem)
+	}
+
+	if slice.Len() == 0 {
+		return false, nil
+	}
+
+	fValue.Set(slice)
+	return true, nil
+}
+
+var envReplacer = strings.NewReplacer(".", "_", "-", "_", "[", "_", "]", "")
+
+// envName returns the environment variable's name of a field, i.e APP_DBCREDENTIALS_HOST.
+func envName(prefix string, f field) string {
+	name := envReplacer.Replace(f.Name)
+	if prefix != "" {
+		name = strings.TrimSuffix(prefix, "_") + "_" + name
+	}
+
+	return strings.ToUpper(name)
+}
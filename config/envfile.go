@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseEnvFile parses the lines of a dotenv file, i.e `DB_HOST=localhost`.
+// Empty lines and lines that start with '#' are skipped, the "export " prefix is allowed,
+// double-quoted values are unquoted as Go strings and single-quoted values are kept as they are.
+func parseEnvFile(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		idx := strings.IndexByte(line, '=')
+		if idx <= 0 {
+			return nil, fmt.Errorf("line %d: expected NAME=value but got: %s", lineNumber, line)
+		}
+
+		name, value := strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:])
+
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", lineNumber, name, err)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			// trailing comment of an unquoted value.
+			if idx := strings.Index(value, " #"); idx != -1 {
+				value = strings.TrimSpace(value[:idx])
+			}
+		}
+
+		values[name] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// decodeEnv is the `FileDecoder` of the "env" format.
+// A struct's fields are matched by their environment variables' names, without a prefix, see `WithEnv`,
+// a generic tree, i.e a map[string]interface{}, is filled with the flat names and values.
+func decodeEnv(data []byte, dest interface{}) error {
+	values, err := parseEnvFile(data)
+	if err != nil {
+		return err
+	}
+
+	switch d := dest.(type) {
+	case *map[string]string:
+		*d = values
+		return nil
+	case *map[string]interface{}:
+		*d = envTree(values)
+		return nil
+	case *interface{}:
+		*d = envTree(values)
+		return nil
+	}
+
+	if !ok(dest) {
+		return ErrBad
+	}
+
+	lookup := func(name string) (string, bool) {
+		got, ok := values[name]
+		return got, ok
+	}
+
+	walkFields(reflect.ValueOf(dest).Elem(), "", defaultNaming, func(f field, fValue reflect.Value) {
+		if !f.Required || err != nil {
+			return
+		}
+
+		if got, ok := values[envName("", f)]; ok {
+			if err = assignString(fValue, f, got); err != nil {
+				err = fmt.Errorf("%s: %w", envName("", f), err)
+			}
+			return
+		}
+
+		if fValue.Kind() == reflect.Slice && isZero(fValue) {
+			_, err = loadIndexedEnv(lookup, "", f, fValue, defaultNaming)
+		}
+	})
+
+	return err
+}
+
+func envTree(values map[string]string) map[string]interface{} {
+	tree := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		tree[name] = value
+	}
+
+	return tree
+}
+
+// encodeEnv is the `FileEncoder` of the "env" format.
+// A struct's non-zero fields are written by their environment variables' names,
+// a generic tree's nested keys are joined by underscores, i.e DB_HOST, and its lists of values by commas.
+func encodeEnv(v interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	switch value := reflect.Indirect(reflect.ValueOf(v)); value.Kind() {
+	case reflect.Struct:
+		err := dumpLines(buf, v, func(f field, value string) string {
+			return envName("", f) + "=" + quoteIfNeeded(value, strconv.Quote)
+		})
+		return buf.Bytes(), err
+	case reflect.Map:
+		leaves := make(map[string]string)
+		if err := flattenEnv("", value.Interface(), leaves); err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(leaves))
+		for name := range leaves {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(buf, "%s=%s\n", name, quoteIfNeeded(leaves[name], strconv.Quote))
+		}
+
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("env: unsupported value of type %T", v)
+	}
+}
+
+func flattenEnv(prefix string, v interface{}, leaves map[string]string) error {
+	join := func(key string) string {
+		name := strings.ToUpper(envReplacer.Replace(key))
+		if prefix == "" {
+			return name
+		}
+		return prefix + "_" + name
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, elem := range value {
+			if err := flattenEnv(join(key), elem, leaves); err != nil {
+				return err
+			}
+		}
+	case map[string]string:
+		for key, elem := range value {
+			leaves[join(key)] = elem
+		}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for i, elem := range value {
+			switch elem.(type) {
+			case map[string]interface{}, []interface{}:
+				// objects are written by their index, i.e PEERS_0_HOST.
+				if err := flattenEnv(join(strconv.Itoa(i)), elem, leaves); err != nil {
+					return err
+				}
+			default:
+				values = append(values, envValue(elem))
+			}
+		}
+
+		if len(values) > 0 {
+			leaves[prefix] = strings.Join(values, ",")
+		}
+	default:
+		if prefix == "" {
+			return fmt.Errorf("env: unsupported value of type %T", v)
+		}
+		leaves[prefix] = envValue(v)
+	}
+
+	return nil
+}
+
+func envValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// FileError is returned when the configuration file can't be read, decoded or migrated.
+type FileError struct {
+	// Path is the file's path.
+	Path string
+	// Line is the line of a decode error, starting from 1, zero if unknown.
+	Line int
+	// Err is the actual error, i.e a `*DecodeError` or a `fs.ErrNotExist` one.
+	Err error
+}
+
+func (e *FileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned when the configuration's contents are not valid for their format,
+// when they are read from a file it's wrapped by a `*FileError`.
+type DecodeError struct {
+	// Format is the registered format's name, i.e "yaml", empty for a custom `FileDecoder`.
+	Format string
+	// Line is the line of the error, starting from 1, zero if unknown.
+	Line int
+	// Err is the decoder's error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Format == "" {
+		return fmt.Sprintf("decode: %v", e.Err)
+	}
+
+	return fmt.Sprintf("decode %s: %v", e.Format, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ConversionError is returned when a source's value can't be converted to its field's type,
+// i.e a "-port=abc" flag for an int field.
+type ConversionError struct {
+	// Field is the field's full name, i.e "Server.Port".
+	Field string
+	// Source is the source of the value, i.e "flag" or "env".
+	Source Source
+	// Key is the source's name of the value, i.e "port" for a flag or "APP_PORT" for an environment variable.
+	Key string
+	// Err describes the expected value, i.e "expected integer, got 'abc'".
+	Err error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("%s: %v (%s %s)", e.Field, e.Err, e.Source, e.Key)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// MissingFieldsError is returned, along with the `*FileError`, when the configuration file
+// does not exist and the rest of the sources did not provide all of the fields.
+// It's returned even if the file exists when the `WithRequired` option is passed.
+type MissingFieldsError struct {
+	// Fields are the missing fields' full names.
+	Fields []string
+}
+
+func (e *MissingFieldsError) Error() string {
+	return "missing configuration fields: " + strings.Join(e.Fields, ", ")
+}
+
+// FieldConflictError is returned when more than one field of the same depth have the same name,
+// i.e the `Host` fields of two embedded structs which are flattened.
+// A `prefix` tag, i.e `prefix:"replica_"`, or a name on the decoder's tag, i.e `yaml:"replica"`, keeps them apart.
+type FieldConflictError struct {
+	// Name is the conflicting name, i.e "Host".
+	Name string
+	// Fields are the Go selectors of the conflicting fields, i.e "Primary.Host" and "Replica.Host".
+	Fields []string
+}
+
+func (e *FieldConflictError) Error() string {
+	return fmt.Sprintf("conflicting fields for %s: %s", e.Name, strings.Join(e.Fields, ", "))
+}
+
+// missingFields returns the names of the fields which are zero and not provided by any source,
+// except the deprecated ones.
+func missingFields(dest interface{}, n naming, provided presence) (names []string) {
+	visitMissingFields(dest, n, provided, func(f field, _ reflect.Value) {
+		if f.Deprecated == "" {
+			names = append(names, f.Name)
+		}
+	})
+
+	return
+}
+
+// isNotExist reports whether the configuration file does not exist,
+// the rest of the sources may provide everything.
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+var lineExpr = regexp.MustCompile(`line (\d+)`)
+
+// newDecodeError returns the `*DecodeError` of a decoder's error, the "data" are used to find the error's line.
+func newDecodeError(format string, data []byte, err error) *DecodeError {
+	decodeErr := &DecodeError{Format: format, Err: err}
+
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		tomlErr   toml.ParseError
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		decodeErr.Line = lineOf(data, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		decodeErr.Line = lineOf(data, typeErr.Offset)
+	case errors.As(err, &tomlErr):
+		decodeErr.Line = tomlErr.Position.Line
+	default:
+		// i.e "yaml: line 3: ..." or "line 3: expected NAME=value".
+		if m := lineExpr.FindStringSubmatch(err.Error()); m != nil {
+			decodeErr.Line, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	return decodeErr
+}
+
+// lineOf returns the line, starting from 1, of a byte offset.
+func lineOf(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	return 1 + strings.Count(string(data[:offset]), "\n")
+}
@@ -0,0 +1,111 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+func TestLoadFileError(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": "Addr: \":8080\"\nYear: [2017\n"})
+
+	var c testConfiguration
+	err := Load("config.yml", &c, WithFS(fsys), WithoutSurvey)
+
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) {
+		t.Fatalf("expected a file error but got: %v", err)
+	}
+
+	if expected, got := "config.yml", fileErr.Path; expected != got {
+		t.Fatalf("expected path: %s but got: %s", expected, got)
+	}
+
+	if fileErr.Line == 0 {
+		t.Fatalf("expected the line of the error but got: %v", err)
+	}
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Format != "yaml" {
+		t.Fatalf("expected a yaml decode error but got: %v", err)
+	}
+}
+
+func TestLoadReaderDecodeErrorLine(t *testing.T) {
+	var c testConfiguration
+	err := LoadReader(strings.NewReader("{\n  \"Addr\": \":8080\",\n  \"Year\": \"2017\"\n}"), "json", &c, WithoutSurvey)
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a decode error but got: %v", err)
+	}
+
+	if expected, got := 3, decodeErr.Line; expected != got {
+		t.Fatalf("expected line: %d but got: %d", expected, got)
+	}
+}
+
+func TestLoadConversionErrors(t *testing.T) {
+	t.Setenv("APP_DEBUG", "maybe")
+
+	var c testConfiguration
+	err := Load("", &c, WithFileDecoder(nil), WithFlags(configtest.Flags("-year", "abc")), WithEnv("APP"), WithoutSurvey)
+
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("expected a conversion error but got: %v", err)
+	}
+
+	if expected, got := "Year: expected integer, got 'abc' (flag -year)", convErr.Error(); expected != got {
+		t.Fatalf("expected error: %s but got: %s", expected, got)
+	}
+
+	if expected := "Debug: expected true or false, got 'maybe' (env APP_DEBUG)"; !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected the joined error to contain: %s but got: %v", expected, err)
+	}
+}
+
+func TestLoadMissingFileProvided(t *testing.T) {
+	var c testFlagsConfiguration
+	flags := configtest.Flags("-addr", ":8080", "-debug=false", "-verbose=false", "-year", "2019")
+	if err := Load("config.yml", &c, WithFS(configtest.FS(nil)), WithFlags(flags), WithoutSurvey); err != nil {
+		t.Fatalf("expected no error when all fields are provided but got: %v", err)
+	}
+
+	c = testFlagsConfiguration{}
+	err := Load("config.yml", &c, WithFS(configtest.FS(nil)), WithFlags(configtest.Flags("-addr", ":8080")), WithoutSurvey)
+
+	var missingErr *MissingFieldsError
+	if !errors.As(err, &missingErr) || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a missing fields and a not exist error but got: %v", err)
+	}
+
+	if expected, got := "Debug, Verbose, Year", strings.Join(missingErr.Fields, ", "); expected != got {
+		t.Fatalf("expected missing fields: %s but got: %s", expected, got)
+	}
+}
+
+func TestLoadRequired(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": "Addr: :8080\nDebug: false"})
+
+	var c testFlagsConfiguration
+	if err := Load("config.yml", &c, WithFS(fsys), WithoutSurvey); err != nil {
+		t.Fatalf("expected no error without the required option but got: %v", err)
+	}
+
+	c = testFlagsConfiguration{}
+	err := Load("config.yml", &c, WithFS(fsys), WithoutSurvey, WithRequired)
+
+	var missingErr *MissingFieldsError
+	if !errors.As(err, &missingErr) || errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a missing fields error only but got: %v", err)
+	}
+
+	if expected, got := "Verbose, Year", strings.Join(missingErr.Fields, ", "); expected != got {
+		t.Fatalf("expected missing fields: %s but got: %s", expected, got)
+	}
+}
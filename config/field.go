@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -42,8 +45,11 @@ func containsTagValue(f reflect.StructField, searchFor string) bool {
 type field struct {
 	// the actual struct's indexes of the field.
 	Index []int
-	// the actual name, the yaml(or other file decoder's tag name) one or the field name.
+	// the actual name, the yaml(or other file decoder's tag name) one or the field name,
+	// see `WithNaming` too.
 	Name string
+	// the prefix of a section's fields' names, i.e "Server." or "db_" of a `prefix:"db_"` tag, see `isSquashed`.
+	Prefix string
 	// if marked as required, by tag.
 	// And as always; a bool false value is zero,
 	// so if required then it will ask for it so make sure that you setup your configuration fields correctly,
@@ -53,6 +59,26 @@ type field struct {
 	// true if it's password/secret, tag value contains "password" or "secret", it's being used
 	// on survey to show a special password prompt.
 	Secret bool
+	// true if the tag value contains "sensitive", its value is never printed as it's,
+	// it's replaced by its fingerprint instead, see `Fingerprint`.
+	Sensitive bool
+
+	// the time layout of a time.Time field, by the "layout" tag, i.e `layout:"2006-01-02"`.
+	// Defaults to the `TimeLayout`.
+	Layout string
+	// the time zone's name of a time.Time field, by the "tz" tag, i.e `tz:"Europe/Athens"`.
+	TZ string
+	// the flag's shorthand, by the "short" tag, i.e `short:"p"`.
+	Short string
+	// the description of the field, by the "help" tag, i.e `help:"the address to listen on"`.
+	// It's used on flags' usage and prompts.
+	Help string
+	// if marked as persistent, by tag, the `BindCommand` declares it as a persistent flag.
+	Persistent bool
+	// the deprecation message, by the "deprecated" tag, i.e `deprecated:"use NewName"`.
+	Deprecated string
+	// the allowed range of a number field, by the "min" and "max" tags, i.e `min:"1" max:"65535"`.
+	Min, Max string
 }
 
 func structFieldIgnored(f reflect.StructField) bool {
@@ -60,7 +86,7 @@ func structFieldIgnored(f reflect.StructField) bool {
 }
 
 func isRequired(f reflect.StructField) bool {
-	if f.Anonymous || f.PkgPath != "" || f.Type.Kind() == reflect.Struct {
+	if f.Anonymous || f.PkgPath != "" || isSection(f.Type) {
 		// skip unexported, anonymous(embedded) and structs from this check, they are always false,
 		// because the check should be happen on the fields of these structs and not on these as structures.
 		return false
@@ -73,42 +99,292 @@ func isSecret(f reflect.StructField) bool {
 	return containsTagValue(f, "password") || containsTagValue(f, "secret")
 }
 
-func lookupFields(typ reflect.Type, parent field) (fields []field) {
-	for i, n := 0, typ.NumField(); i < n; i++ {
+// isSection reports whether a field's type is a struct which its fields should be visited,
+// time.Time and the `Setter` implementations are structs too but their values are set as a whole.
+func isSection(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != timeType && !isSetter(typ)
+}
+
+// childPath returns the full index and name of a parent's field.
+func childPath(parent field, i int, name string) ([]int, string) {
+	index := make([]int, len(parent.Index), len(parent.Index)+1)
+	copy(index, parent.Index)
+	return append(index, i), parent.Prefix + name
+}
+
+// isSquashed reports whether the fields of a struct field are flattened to its parent, as the Go promotion does,
+// i.e an embedded struct without a name on its decoder's tag or a struct tagged as `yaml:",inline"` or 'config:"squash"'.
+// A struct with a "prefix" tag, i.e `prefix:"db_"`, is flattened too but its fields' names are prefixed.
+func isSquashed(f reflect.StructField, n naming) bool {
+	if containsTagValue(f, "squash") {
+		return true
+	}
+
+	if _, ok := f.Tag.Lookup("prefix"); ok {
+		return true
+	}
+
+	if hasTagOption(f, n.tag, "inline") {
+		return true
+	}
+
+	return f.Anonymous && strings.Split(f.Tag.Get(n.tag), ",")[0] == ""
+}
+
+// isInline reports whether the file decoder of the naming flattens a struct field to its parent, i.e on templates,
+// the yaml decoder flattens the `yaml:",inline"` ones and the json and toml decoders the embedded ones without a name.
+func isInline(f reflect.StructField, n naming) bool {
+	if n.tag == "yaml" {
+		return hasTagOption(f, n.tag, "inline")
+	}
+
+	return f.Anonymous && n.tag != "" && strings.Split(f.Tag.Get(n.tag), ",")[0] == ""
+}
+
+// hasTagOption reports whether a tag's options, after its name, contain the "option", i.e `yaml:",inline"`.
+func hasTagOption(f reflect.StructField, key, option string) bool {
+	values := strings.Split(f.Tag.Get(key), ",")
+	for _, v := range values[1:] {
+		if v == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newSection returns the section of a struct field of the "parent",
+// a squashed section has the name of its parent, see `isSquashed`.
+func newSection(parent field, i int, f reflect.StructField, n naming) field {
+	index, name := childPath(parent, i, n.fieldName(f))
+	if !isSquashed(f, n) {
+		return field{Name: name, Index: index, Prefix: name + "."}
+	}
+
+	return field{Name: parent.Name, Index: index, Prefix: parent.Prefix + f.Tag.Get("prefix")}
+}
+
+// lookupSections returns the "parent" and its nested structs, recursively,
+// the same ones that the `lookupFields` walks through.
+func lookupSections(typ reflect.Type, parent field, n naming) []field {
+	sections := []field{parent}
+
+	for i, numField := 0, typ.NumField(); i < numField; i++ {
+		f := typ.Field(i)
+		if f.Type.Kind() == reflect.Ptr || f.PkgPath != "" && !f.Anonymous || !isSection(f.Type) || structFieldIgnored(f) {
+			continue
+		}
+
+		sections = append(sections, lookupSections(f.Type, newSection(parent, i, f, n), n)...)
+	}
+
+	return sections
+}
+
+// lookupFields returns the fields of a struct type and of its nested structs, recursively.
+// A field which is shadowed by a shallower one of the same name is dropped, as the Go promotion does,
+// i.e a `Host` field hides the `Host` of an embedded struct. See `fieldConflicts` for the ones of the same depth.
+func lookupFields(typ reflect.Type, parent field, n naming) []field {
+	fields := collectFields(typ, parent, n)
+
+	depths := make(map[string]int, len(fields))
+	for _, f := range fields {
+		if depth, ok := depths[f.Name]; !ok || len(f.Index) < depth {
+			depths[f.Name] = len(f.Index)
+		}
+	}
+
+	if len(depths) == len(fields) {
+		return fields
+	}
+
+	promoted := make([]field, 0, len(depths))
+	for _, f := range fields {
+		if depth, ok := depths[f.Name]; ok && len(f.Index) == depth {
+			promoted = append(promoted, f)
+			delete(depths, f.Name) // the first one of a conflict is kept.
+		}
+	}
+
+	return promoted
+}
+
+func collectFields(typ reflect.Type, parent field, n naming) (fields []field) {
+	for i, numField := 0, typ.NumField(); i < numField; i++ {
 		f := typ.Field(i)
 
 		if f.Type.Kind() == reflect.Ptr {
 			continue // skip pointers.
 		}
 
-		index := []int{i}
-
-		// embedded.
-		if f.Type.Kind() == reflect.Struct && !structFieldIgnored(f) {
-			fields = append(fields, lookupFields(f.Type, field{
-				Name:  f.Name,
-				Index: index,
-			})...)
+		// nested or embedded.
+		if isSection(f.Type) && !structFieldIgnored(f) {
+			fields = append(fields, collectFields(f.Type, newSection(parent, i, f, n), n)...)
 			continue
 		}
 
-		required := isRequired(f)
-		name := f.Name
+		index, name := childPath(parent, i, n.fieldName(f))
+		fields = append(fields, newField(f, index, name))
+	}
 
-		if len(parent.Index) > 0 && parent.Index[0] >= 0 {
-			index = append(parent.Index, index...)
-			name = parent.Name + "." + name
+	return
+}
+
+// fieldConflicts returns a `*FieldConflictError` for each name which is provided by more than one field of the same depth,
+// i.e the `Host` fields of two embedded structs, the `lookupFields` keeps only the first of them.
+func fieldConflicts(typ reflect.Type, n naming) error {
+	fields := collectFields(typ, field{}, n)
+
+	var names []string
+	byName := make(map[string][]field)
+	for _, f := range fields {
+		if _, ok := byName[f.Name]; !ok {
+			names = append(names, f.Name)
 		}
+		byName[f.Name] = append(byName[f.Name], f)
+	}
 
-		field := field{
-			Name:     name,
-			Index:    index,
-			Required: required,
-			Secret:   isSecret(f),
+	var errs []error
+	for _, name := range names {
+		depth := -1
+		var paths []string
+		for _, f := range byName[name] {
+			switch {
+			case depth == -1 || len(f.Index) < depth:
+				depth, paths = len(f.Index), []string{goPath(typ, f.Index)}
+			case len(f.Index) == depth:
+				paths = append(paths, goPath(typ, f.Index))
+			}
 		}
 
-		fields = append(fields, field)
+		if len(paths) > 1 {
+			errs = append(errs, &FieldConflictError{Name: name, Fields: paths})
+		}
 	}
 
-	return
+	return errors.Join(errs...)
+}
+
+// goPath returns the Go selector of a field's index, i.e "Primary.Host".
+func goPath(typ reflect.Type, index []int) string {
+	names := make([]string, len(index))
+	for i, idx := range index {
+		f := typ.Field(idx)
+		names[i], typ = f.Name, f.Type
+	}
+
+	return strings.Join(names, ".")
+}
+
+// newField returns the field of a non-struct "f" by its tags.
+func newField(f reflect.StructField, index []int, name string) field {
+	return field{
+		Name:       name,
+		Index:      index,
+		Required:   isRequired(f),
+		Secret:     isSecret(f),
+		Sensitive:  containsTagValue(f, "sensitive"),
+		Layout:     f.Tag.Get("layout"),
+		TZ:         f.Tag.Get("tz"),
+		Short:      f.Tag.Get("short"),
+		Help:       f.Tag.Get("help"),
+		Persistent: containsTagValue(f, "persistent"),
+		Deprecated: f.Tag.Get("deprecated"),
+		Min:        f.Tag.Get("min"),
+		Max:        f.Tag.Get("max"),
+	}
+}
+
+// isSectionList reports whether a field's type is a slice or a string-keyed map of sections,
+// i.e []Upstream or map[string]Backend. The fields of their elements are visited by `walkFields`,
+// with indexed and keyed names, i.e "Upstreams[0].Host" and "Backends.api.URL".
+func isSectionList(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Slice:
+		return isSection(typ.Elem())
+	case reflect.Map:
+		return typ.Key().Kind() == reflect.String && isSection(typ.Elem())
+	default:
+		return false
+	}
+}
+
+// joinName returns the full name of a child, i.e "Upstreams[0].Host".
+func joinName(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	if name == "" {
+		return parent
+	}
+
+	return parent + "." + name
+}
+
+// walkFields calls "fn" for each field of the struct value "v", the same ones that the `lookupFields` returns,
+// and for each field of the elements of its slices and maps of sections, recursively, see `isSectionList`.
+// The fields' names are prefixed by the "prefix", if not empty, and their Index is relative to their own struct,
+// so the "fValue" should be used instead.
+func walkFields(v reflect.Value, prefix string, n naming, fn func(f field, fValue reflect.Value)) {
+	for _, f := range lookupFields(v.Type(), field{}, n) {
+		f.Name = joinName(prefix, f.Name)
+		fValue := v.FieldByIndex(f.Index)
+		fn(f, fValue)
+
+		if f.Required && isSectionList(fValue.Type()) {
+			visitElements(fValue, f.Name, func(name string, elem reflect.Value) {
+				walkFields(elem, name, n, fn)
+			})
+		}
+	}
+}
+
+// walkSections calls "fn" for the struct value "v" and for each one of its nested structs, recursively,
+// including the elements of its slices and maps of sections, see `walkFields`.
+// The sections' names and prefixes are prefixed by the "name", if not empty.
+func walkSections(v reflect.Value, name string, n naming, fn func(section field, sectionVal reflect.Value)) {
+	for _, section := range lookupSections(v.Type(), field{}, n) {
+		if name != "" {
+			section.Name, section.Prefix = joinName(name, section.Name), name+"."+section.Prefix
+		}
+
+		fn(section, v.FieldByIndex(section.Index))
+	}
+
+	for _, f := range lookupFields(v.Type(), field{}, n) {
+		if fValue := v.FieldByIndex(f.Index); f.Required && isSectionList(fValue.Type()) {
+			visitElements(fValue, joinName(name, f.Name), func(name string, elem reflect.Value) {
+				walkSections(elem, name, n, fn)
+			})
+		}
+	}
+}
+
+// visitElements calls "fn" for each element of a slice, by index, or a map, by sorted key.
+// The map elements are not addressable, so a copy of them is visited instead
+// and it's stored back to the map if "fn" changed it.
+func visitElements(v reflect.Value, name string, fn func(name string, elem reflect.Value)) {
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			fn(fmt.Sprintf("%s[%d]", name, i), v.Index(i))
+		}
+
+		return
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	for _, key := range keys {
+		before := v.MapIndex(key).Interface()
+		elem := reflect.New(v.Type().Elem()).Elem()
+		elem.Set(v.MapIndex(key))
+
+		fn(name+"."+key.String(), elem)
+
+		if !reflect.DeepEqual(before, elem.Interface()) {
+			v.SetMapIndex(key, elem)
+		}
+	}
 }
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// WithFS makes `Load` to read the configuration file from the "fsys"
+// file system instead of the operating system's one, i.e an `embed.FS`.
+// The file's path is cleaned, so "./config.yml" and "config.yml" are the same.
+//
+// See `LoadFS` too.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// WithSearchPaths makes `Load` to look for the configuration file
+// inside the "dirs", in order, and read the first one that exists,
+// i.e WithSearchPaths("./", "$HOME/.app", "/etc/app").
+// Environment variables in the "dirs" are expanded.
+//
+// Has no effect if the file's path is an absolute one.
+func WithSearchPaths(dirs ...string) Option {
+	return func(o *options) {
+		o.searchPaths = dirs
+	}
+}
+
+// LoadReader same as `Load` but it reads the configuration's contents from the "r"
+// and decodes them based on the "format", i.e "yaml" or "json", see `RegisterDecoder`.
+func LoadReader(r io.Reader, format string, dest interface{}, optional ...Option) error {
+	if !ok(dest) {
+		return ErrBad
+	}
+
+	opts := newOptions(append(optional[:len(optional):len(optional)], WithFormat(format)))
+	if err := fieldConflicts(reflectType(dest), opts.naming); err != nil {
+		return err
+	}
+
+	opts.report.recordDefaults(dest, opts.naming)
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return next(dest, err, opts)
+	}
+
+	return decode(data, "", dest, opts)
+}
+
+// LoadFS same as `Load` but it reads the configuration file from the "fsys"
+// file system, i.e an `embed.FS` or an archive, see `WithFS` too.
+func LoadFS(fsys fs.FS, name string, dest interface{}, optional ...Option) error {
+	return Load(name, dest, append(optional[:len(optional):len(optional)], WithFS(fsys))...)
+}
+
+// readFile returns the contents of the configuration file and its resolved path.
+func readFile(fullpath string, opts options) ([]byte, string, error) {
+	if len(opts.searchPaths) > 0 && !filepath.IsAbs(fullpath) {
+		found, err := search(fullpath, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		fullpath = found
+	}
+
+	if opts.fsys != nil {
+		data, err := fs.ReadFile(opts.fsys, fsPath(fullpath))
+		return data, fullpath, err
+	}
+
+	// get the abs
+	// which will try to find the 'fullpath' from current workind dir too.
+	f, err := filepath.Abs(fullpath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := ioutil.ReadFile(f)
+	return data, f, err
+}
+
+// fsPath converts an operating system's file path to a valid `fs.FS` one.
+func fsPath(name string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+}
+
+// search returns the first existing file of the "name" inside the search paths.
+func search(name string, opts options) (string, error) {
+	for _, dir := range opts.searchPaths {
+		fullpath := filepath.Join(os.ExpandEnv(dir), name)
+
+		var err error
+		if opts.fsys != nil {
+			_, err = fs.Stat(opts.fsys, fsPath(fullpath))
+		} else {
+			_, err = os.Stat(fullpath)
+		}
+
+		if err == nil {
+			return fullpath, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: not found in %s: %w", name, strings.Join(opts.searchPaths, ", "), fs.ErrNotExist)
+}
@@ -0,0 +1,57 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+func TestLoadReader(t *testing.T) {
+	var c testConfiguration
+	r := strings.NewReader(`{"Addr": ":8080", "DB": {"Host": "localhost"}}`)
+	if err := LoadReader(r, "json", &c, WithoutSurvey); err != nil {
+		t.Fatal(err)
+	}
+
+	configtest.AssertLoaded(t, c, testConfiguration{Addr: ":8080", DB: testDBCredentials{Host: "localhost"}})
+
+	if err := LoadReader(strings.NewReader(""), "ini", &c, WithoutSurvey); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
+
+func TestLoadSearchPaths(t *testing.T) {
+	fsys := configtest.FS(map[string]string{
+		"etc/app/config.yml":   "Addr: :80",
+		"home/.app/config.yml": "Addr: :8080",
+	})
+
+	var c testConfiguration
+	if err := LoadFS(fsys, "config.yml", &c, WithSearchPaths("./", "home/.app", "etc/app"), WithoutSurvey); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := ":8080", c.Addr; expected != got {
+		t.Fatalf("expected the first existing file to be loaded, Addr should be %s but got: %s", expected, got)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yml"), []byte("Addr: :443"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("CONFIG_TEST_DIR", dir)
+	defer os.Unsetenv("CONFIG_TEST_DIR")
+
+	c = testConfiguration{}
+	if err := Load("config.yml", &c, WithSearchPaths("$CONFIG_TEST_DIR/missing", "$CONFIG_TEST_DIR"), WithoutSurvey); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := ":443", c.Addr; expected != got {
+		t.Fatalf("Addr should be %s but got: %s", expected, got)
+	}
+}
@@ -1,12 +1,80 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"os"
 	"reflect"
 	"strings"
 )
 
+// FlagSource is the interface which should be implemented by the
+// command line flag sets in order to be used as a configuration source.
+//
+// See `StdFlags`, `PFlags` and `WithFlagSource`.
+type FlagSource interface {
+	// Parsed reports whether the command line arguments are parsed already.
+	Parsed() bool
+	// Parse parses the command line arguments, without the program's name.
+	Parse(arguments []string) error
+	// Lookup returns the value of a flag by its name or, if not declared, by its shorthand
+	// and reports whether any of them was declared. The "shorthand" may be empty.
+	Lookup(name, shorthand string) (value string, ok bool)
+}
+
+// FlagChecker is an optional interface of a `FlagSource` which reports
+// whether a flag, by its name or its shorthand, was set on the command line, not just declared.
+// The fields of the flags that were set are provided even if their values are zero, i.e "-debug=false",
+// so they are not filled by the next sources or asked. The `StdFlags` and `PFlags` implement it.
+type FlagChecker interface {
+	Changed(name, shorthand string) bool
+}
+
+type stdFlags struct {
+	set *flag.FlagSet
+}
+
+// StdFlags returns a `FlagSource` of a standard flag set, i.e flag.CommandLine.
+// The shorthand flags are just declared flags with a shorter name.
+func StdFlags(set *flag.FlagSet) FlagSource {
+	return stdFlags{set}
+}
+
+func (s stdFlags) Parsed() bool {
+	return s.set.Parsed()
+}
+
+func (s stdFlags) Parse(arguments []string) error {
+	return s.set.Parse(arguments)
+}
+
+func (s stdFlags) Lookup(name, shorthand string) (string, bool) {
+	arg := s.set.Lookup(name)
+	if shorthand != "" {
+		// the shorthand is a different flag, prefer the one that was actually set.
+		if short := s.set.Lookup(shorthand); short != nil && (arg == nil || s.isSet(shorthand) && !s.isSet(name)) {
+			arg = short
+		}
+	}
+
+	if arg == nil {
+		return "", false
+	}
+
+	return arg.Value.String(), true
+}
+
+func (s stdFlags) Changed(name, shorthand string) bool {
+	return s.isSet(name) || shorthand != "" && s.isSet(shorthand)
+}
+
+func (s stdFlags) isSet(name string) (found bool) {
+	s.set.Visit(func(f *flag.Flag) {
+		found = found || f.Name == name
+	})
+	return
+}
+
 // TryLoadFlags tries to load the "dest" configuration from a flag set.
 // For command line applications the flagset you should provide is the `flag.CommandLine`.
 //
@@ -14,26 +82,137 @@ import (
 // executable name for command line applications (os.Args[1:]).
 // The flags may or may not be parsed already.
 //
-// Note that the end-user should declare the needed flags otherwise this will panic.
+// Note that the end-user should declare the needed flags, see `BindFlags`.
+// Returns a `*ConversionError`, or a join of them, for the values that can't be converted to their fields' types.
 func TryLoadFlags(set *flag.FlagSet, dest interface{}) error {
+	return TryLoadFlagSource(StdFlags(set), dest)
+}
+
+// TryLoadFlagSource same as `TryLoadFlags` but it accepts any `FlagSource`.
+func TryLoadFlagSource(src FlagSource, dest interface{}) error {
 	if !ok(dest) {
 		return ErrBad
 	}
 
-	if !set.Parsed() {
-		if err := set.Parse(os.Args[1:]); err != nil {
+	return tryLoadFlags(src, dest, defaultNaming, nil)
+}
+
+func tryLoadFlags(src FlagSource, dest interface{}, n naming, provided presence) error {
+	if !src.Parsed() {
+		if err := src.Parse(os.Args[1:]); err != nil {
 			return err
 		}
 	}
 
-	visitMissingFields(dest, func(f field, fValue reflect.Value) {
-		name := strings.ToLower(f.Name) // even if customized Name is capitalized, flag's name should be all lowercase.
-		arg := set.Lookup(name)
-		if arg != nil {
-			value := parseString(arg.Value.String(), fValue.Type())
-			fValue.Set(reflect.ValueOf(value))
+	checker, _ := src.(FlagChecker)
+
+	var errs []error
+	visitMissingFields(dest, n, provided, func(f field, fValue reflect.Value) {
+		if got, ok := src.Lookup(n.flagName(f), f.Short); ok {
+			changed := checker != nil && checker.Changed(n.flagName(f), f.Short)
+			if got == "" && !changed {
+				return // declared without a default value.
+			}
+
+			if err := assignString(fValue, f, got); err != nil {
+				errs = append(errs, &ConversionError{Field: f.Name, Source: SourceFlag, Key: "-" + n.flagName(f), Err: err})
+				return
+			}
+
+			if changed {
+				provided.add(f.Name)
+			}
 		}
 	})
 
+	return errors.Join(errs...)
+}
+
+// flagName returns the flag's name of a field,
+// even if customized Name is capitalized, flag's name should be all lowercase,
+// unless a naming strategy is set, i.e "maxConns" of the `CamelCase` is kept as it's.
+func (n naming) flagName(f field) string {
+	if n.strategy != nil {
+		return f.Name
+	}
+
+	return strings.ToLower(f.Name)
+}
+
+// BindFlags declares a flag to the "set" for each one of the "dest" configuration's fields,
+// a boolean flag for boolean fields, a repeatable flag for slices and maps, i.e "-peers a -peers b,c"
+// and "-labels env=prod", and a string flag for the rest of them.
+// The flags' default values are the fields' current values
+// and their usage is the fields' "help" tag. A field with a "short" tag
+// declares a second flag with the shorthand name as well.
+// Flags that are already declared are skipped.
+// The `ConfigFlag`, with its shorthand, and the `ConfigFormatFlag` are declared too, after the fields' flags,
+// unless a field uses the same name or shorthand, so `Load` reads the configuration files of the command line.
+//
+// The "optional" options should be the ones of `Load`, so the flags' names are resolved by the same
+// `WithNaming` and `WithFormat`, i.e "-max_conns" for the `SnakeCase`, the rest of the options are ignored.
+//
+// Call it before the flags are parsed.
+func BindFlags(set *flag.FlagSet, dest interface{}, optional ...Option) error {
+	n := newOptions(optional).naming
+	return bindFlags(dest, n, func(short string) { bindConfigFlags(set, short) }, func(f field, fValue reflect.Value) {
+		var list *listFlag // shared by the name and the shorthand.
+		for _, name := range []string{n.flagName(f), f.Short} {
+			if name == "" || set.Lookup(name) != nil {
+				continue
+			}
+
+			if isCollection(fValue.Type()) {
+				if list == nil {
+					list = newListFlag(f, fValue)
+				}
+				set.Var(list, name, f.Help)
+				continue
+			}
+
+			if fValue.Kind() == reflect.Bool {
+				set.Bool(name, fValue.Bool(), f.Help)
+				continue
+			}
+
+			set.String(name, flagDefault(f, fValue), f.Help)
+		}
+	})
+}
+
+func bindFlags(dest interface{}, n naming, declareConfig func(short string), declare func(f field, fValue reflect.Value)) error {
+	if !ok(dest) {
+		return ErrBad
+	}
+
+	if err := fieldConflicts(reflectType(dest), n); err != nil {
+		return err
+	}
+
+	walkFields(reflect.ValueOf(dest).Elem(), "", n, func(f field, fValue reflect.Value) {
+		if f.Required && !isSectionList(fValue.Type()) {
+			declare(f, fValue)
+		}
+	})
+
+	// the fields' flags are declared first, so they keep their names and shorthands.
+	if !hasFlag(dest, n, ConfigFlag) {
+		short := ConfigFlagShort
+		if hasFlag(dest, n, short) {
+			short = ""
+		}
+		declareConfig(short)
+	}
+
 	return nil
 }
+
+// flagDefault returns the default value of a field's flag, it's empty for the secret and sensitive fields
+// so they are not shown on the usage, their value is kept as the flag is not set.
+func flagDefault(f field, fValue reflect.Value) string {
+	if isZero(fValue) || f.Secret || f.Sensitive {
+		return ""
+	}
+
+	return formatValue(f, fValue)
+}
@@ -0,0 +1,99 @@
+package config_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+
+	"github.com/spf13/pflag"
+)
+
+type testFlagsConfiguration struct {
+	Addr    string `short:"a" help:"the address to listen on"`
+	Debug   bool   `short:"d"`
+	Verbose bool   `config:"persistent"`
+	Year    int
+}
+
+func TestBindFlags(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+
+	c := testFlagsConfiguration{Year: 2017}
+	if err := BindFlags(set, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "the address to listen on", set.Lookup("addr").Usage; expected != got {
+		t.Fatalf("expected usage: %s but got: %s", expected, got)
+	}
+
+	if err := set.Parse([]string{"-a", ":8080", "-d"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c = testFlagsConfiguration{}
+	report := configtest.Load(t, "", &c, WithFileDecoder(nil), WithFlags(set), WithoutSurvey)
+	configtest.AssertLoaded(t, c, testFlagsConfiguration{Addr: ":8080", Debug: true, Year: 2017})
+	configtest.AssertSource(t, report, "Addr", "flag")
+}
+
+func TestBindFlagsNaming(t *testing.T) {
+	type namingConfiguration struct {
+		MaxConns int `short:"m"`
+	}
+
+	set := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	var c namingConfiguration
+	if err := BindPFlags(set, &c, WithNaming(SnakeCase)); err != nil {
+		t.Fatal(err)
+	}
+
+	if set.Lookup("max_conns") == nil {
+		t.Fatalf("expected the max_conns flag to be declared")
+	}
+
+	if err := set.Parse([]string{"--max_conns", "10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	report := configtest.Load(t, "", &c, WithFileDecoder(nil), WithNaming(SnakeCase), WithFlagSource(PFlags(set)), WithoutSurvey)
+	configtest.AssertLoaded(t, c, namingConfiguration{MaxConns: 10})
+	configtest.AssertSource(t, report, "max_conns", "flag")
+}
+
+type testCommand struct {
+	flags, persistentFlags *pflag.FlagSet
+}
+
+func (cmd *testCommand) Flags() *pflag.FlagSet           { return cmd.flags }
+func (cmd *testCommand) PersistentFlags() *pflag.FlagSet { return cmd.persistentFlags }
+
+func TestBindCommand(t *testing.T) {
+	root := &testCommand{pflag.NewFlagSet("root", pflag.ContinueOnError), pflag.NewFlagSet("root", pflag.ContinueOnError)}
+
+	var c testFlagsConfiguration
+	if err := BindCommand(root, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if root.PersistentFlags().Lookup("verbose") == nil || root.Flags().Lookup("verbose") != nil {
+		t.Fatalf("expected verbose to be declared as a persistent flag only")
+	}
+
+	// a subcommand inherits the persistent flags, as cobra does on execution.
+	sub := &testCommand{pflag.NewFlagSet("sub", pflag.ContinueOnError), pflag.NewFlagSet("sub", pflag.ContinueOnError)}
+	sub.Flags().AddFlagSet(root.PersistentFlags())
+	sub.Flags().StringP("addr", "a", "", "")
+	sub.Flags().Int("year", 0, "")
+	if err := sub.Flags().Parse([]string{"-a", ":8080", "--verbose", "--year=2019"}); err != nil {
+		t.Fatal(err)
+	}
+
+	configtest.Load(t, "", &c, WithFileDecoder(nil), WithCommand(sub), WithoutSurvey)
+	configtest.AssertLoaded(t, c, testFlagsConfiguration{Addr: ":8080", Verbose: true, Year: 2019})
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+var decoders = map[string]FileDecoder{
+	"yaml": yaml.Unmarshal,
+	"yml":  yaml.Unmarshal,
+	"json": decodeJSON,
+	"toml": toml.Unmarshal,
+	"env":  decodeEnv,
+}
+
+// FileEncoder is the supported kind of function that
+// encodes a configuration, a struct or a generic tree of map[string]interface{} values, to a file's contents.
+//
+// See `RegisterEncoder`.
+type FileEncoder func(v interface{}) ([]byte, error)
+
+// encoders are the marshalers of the formats that the configuration can be written to.
+var encoders = map[string]FileEncoder{
+	"yaml": yaml.Marshal,
+	"yml":  yaml.Marshal,
+	"json": func(v interface{}) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false) // i.e "<secret>".
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	},
+	"toml": func(v interface{}) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		if err := toml.NewEncoder(buf).Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	},
+	"env": encodeEnv,
+}
+
+// RegisterDecoder registers a `FileDecoder` for a format's name, i.e "hcl",
+// so it can be used by the `WithFormat` option and the `LoadReader` function.
+// The "yaml" (and "yml"), "json", "toml" and "env" (dotenv) formats are registered by default.
+//
+// See `RegisterEncoder` too.
+func RegisterDecoder(format string, fileDecoder FileDecoder) {
+	decoders[normalizeFormat(format)] = fileDecoder
+}
+
+// RegisterEncoder registers a `FileEncoder` for a format's name, i.e "hcl",
+// so configurations can be written in that format, i.e by `Convert`, `Dump` and `Template`.
+// The "yaml" (and "yml"), "json", "toml" and "env" (dotenv) formats are registered by default.
+func RegisterEncoder(format string, fileEncoder FileEncoder) {
+	encoders[normalizeFormat(format)] = fileEncoder
+}
+
+// normalizeFormat accepts file extensions as format names too, i.e ".yml".
+func normalizeFormat(format string) string {
+	return strings.ToLower(strings.TrimPrefix(format, "."))
+}
+
+func decoderFor(format string) FileDecoder {
+	if fileDecoder, ok := decoders[normalizeFormat(format)]; ok {
+		return fileDecoder
+	}
+
+	return func([]byte, interface{}) error {
+		return fmt.Errorf("unknown configuration format: %q", format)
+	}
+}
+
+// WithFormat changes the file decoder to the one that is registered for the "format",
+// i.e "json", see `RegisterDecoder` and `WithFileDecoder` too.
+// The fields' names are resolved by the format's tag as well, i.e `json:"addr"`.
+//
+// Defaults to "yaml".
+func WithFormat(format string) Option {
+	return func(o *options) {
+		o.fileDecoder = decoderFor(format)
+		o.format = format
+		o.naming = namingFor(format, o.naming.strategy)
+	}
+}
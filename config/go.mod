@@ -1,8 +1,18 @@
 module github.com/kataras/pkg/config
 
-go 1.13
+go 1.20
 
 require (
 	github.com/AlecAivazis/survey/v2 v2.0.4
+	github.com/BurntSushi/toml v1.3.2
+	github.com/spf13/pflag v1.0.5
 	gopkg.in/yaml.v2 v2.2.5
 )
+
+require (
+	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51 // indirect
+	github.com/mattn/go-colorable v0.1.2 // indirect
+	github.com/mattn/go-isatty v0.0.8 // indirect
+	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b // indirect
+	golang.org/x/sys v0.0.0-20190530182044-ad28b68e88f1 // indirect
+)
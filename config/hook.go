@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Hooks are functions which are called on each stage of `Load`, i.e to normalize the values,
+// trim spaces or lowercase host names, to set computed fields or to log audit events.
+// Any of them can be nil. A hook's error stops the `Load` and it's returned as it's, wrapped by the stage's name.
+//
+// The fields that are changed by the hooks are reported as `SourceHook`.
+//
+// See `WithHooks`.
+type Hooks struct {
+	// BeforeDecode is called with the configuration file's contents before they are migrated and decoded,
+	// it returns the contents that should be decoded instead, i.e to expand environment variables.
+	BeforeDecode func(data []byte) ([]byte, error)
+	// AfterDecode is called right after the configuration file is decoded successfully.
+	AfterDecode func(dest interface{}) error
+	// BeforePrompt is called after the key/value store, flags, environment variables and the secret store
+	// and before the survey, even if it's disabled, so the missing fields can be filled before they are asked.
+	BeforePrompt func(dest interface{}) error
+	// AfterPrompt is called after the survey, even if it's disabled, and before the validation.
+	AfterPrompt func(dest interface{}) error
+	// AfterLoad is called last, after a successful validation.
+	AfterLoad func(dest interface{}) error
+}
+
+// WithHooks registers functions to be called on each stage of `Load`, see `Hooks`.
+// It can be used more than once, the hooks of the same stage are called in the order they were registered.
+func WithHooks(hooks Hooks) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, hooks)
+	}
+}
+
+// Setter is the interface which a field's type can implement, through its pointer,
+// in order to customize how it's set from a raw string, i.e a flag's, an environment variable's or an answer's value.
+// A struct type which implements it is set as a whole, its fields are not visited.
+// Note that the file decoders use their own interfaces, i.e yaml.Unmarshaler.
+//
+// Its signature is the same as the flag.Value's Set method.
+type Setter interface {
+	Set(s string) error
+}
+
+var setterType = reflect.TypeOf((*Setter)(nil)).Elem()
+
+// isSetter reports whether the type implements the `Setter` through its pointer.
+func isSetter(typ reflect.Type) bool {
+	return reflect.PtrTo(typ).Implements(setterType)
+}
+
+// runHooks calls the hooks of a stage, the "pick" returns the stage's hook, if any.
+func runHooks(stage string, dest interface{}, opts options, pick func(h Hooks) func(dest interface{}) error) error {
+	for _, hooks := range opts.hooks {
+		hook := pick(hooks)
+		if hook == nil {
+			continue
+		}
+
+		var err error
+		opts.report.record(dest, opts.naming, SourceHook, func() {
+			err = hook(dest)
+		})
+		if err != nil {
+			return fmt.Errorf("%s hook: %w", stage, err)
+		}
+	}
+
+	return nil
+}
+
+// runBeforeDecode calls the BeforeDecode hooks, in order, and returns the final contents.
+func runBeforeDecode(data []byte, opts options) ([]byte, error) {
+	for _, hooks := range opts.hooks {
+		if hooks.BeforeDecode == nil {
+			continue
+		}
+
+		var err error
+		if data, err = hooks.BeforeDecode(data); err != nil {
+			return nil, fmt.Errorf("before decode hook: %w", err)
+		}
+	}
+
+	return data, nil
+}
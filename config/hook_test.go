@@ -0,0 +1,138 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+type testHooksConfiguration struct {
+	Host     string
+	Port     int
+	Addr     string
+	Password string `config:"password"`
+}
+
+func TestLoadHooks(t *testing.T) {
+	var stages []string
+	stage := func(name string) func(dest interface{}) error {
+		return func(dest interface{}) error {
+			stages = append(stages, name)
+			return nil
+		}
+	}
+
+	fsys := configtest.FS(map[string]string{"config.yml": "host: \" Example.COM \"\nport: ${TEST_PORT}\n"})
+	t.Setenv("TEST_PORT", "8080")
+
+	c := testHooksConfiguration{}
+	report := configtest.Load(t, "config.yml", &c,
+		WithFS(fsys),
+		WithPrompter(configtest.Answers(map[string]string{"Password": "123"})),
+		WithHooks(Hooks{
+			BeforeDecode: func(data []byte) ([]byte, error) {
+				stages = append(stages, "before decode")
+				return []byte(os.ExpandEnv(string(data))), nil
+			},
+			AfterDecode: func(dest interface{}) error {
+				stages = append(stages, "after decode")
+				c := dest.(*testHooksConfiguration)
+				c.Host = strings.ToLower(strings.TrimSpace(c.Host))
+				return nil
+			},
+			BeforePrompt: func(dest interface{}) error {
+				stages = append(stages, "before prompt")
+				c := dest.(*testHooksConfiguration)
+				c.Addr = c.Host + ":" + strconv.Itoa(c.Port)
+				return nil
+			},
+			AfterPrompt: stage("after prompt"),
+			AfterLoad:   stage("after load"),
+		}),
+		WithHooks(Hooks{AfterLoad: stage("after load 2")}))
+
+	configtest.AssertLoaded(t, c, testHooksConfiguration{Host: "example.com", Port: 8080, Addr: "example.com:8080", Password: "123"})
+	configtest.AssertSource(t, report, "Host", "hook")
+	configtest.AssertSource(t, report, "Addr", "hook")
+	configtest.AssertSource(t, report, "Password", "prompt")
+
+	if expected, got := "before decode, after decode, before prompt, after prompt, after load, after load 2", strings.Join(stages, ", "); expected != got {
+		t.Fatalf("expected stages: %s but got: %s", expected, got)
+	}
+}
+
+func TestLoadHooksError(t *testing.T) {
+	errStop := errors.New("stop")
+	afterLoad := false
+
+	var c testHooksConfiguration
+	err := Load("", &c, WithFileDecoder(nil), WithoutSurvey, WithHooks(Hooks{
+		AfterPrompt: func(interface{}) error { return errStop },
+		AfterLoad:   func(interface{}) error { afterLoad = true; return nil },
+	}))
+
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected the hook's error but got: %v", err)
+	}
+
+	if afterLoad {
+		t.Fatalf("expected the next hooks to be skipped")
+	}
+}
+
+type testHostname string
+
+func (h *testHostname) Set(s string) error {
+	if strings.ContainsAny(s, " /") {
+		return fmt.Errorf("invalid host name: %q", s)
+	}
+
+	*h = testHostname(strings.ToLower(s))
+	return nil
+}
+
+type testEndpoint struct {
+	Host string
+	Port int
+}
+
+func (e *testEndpoint) Set(s string) error {
+	host, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return fmt.Errorf("expected host:port, got '%s'", s)
+	}
+
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return err
+	}
+
+	e.Host, e.Port = host, n
+	return nil
+}
+
+type testSetterConfiguration struct {
+	Host     testHostname
+	Endpoint testEndpoint
+}
+
+func TestLoadSetter(t *testing.T) {
+	var c testSetterConfiguration
+	flags := configtest.Flags("-host", "Example.COM", "-endpoint", "localhost:8080")
+	configtest.Load(t, "", &c, WithFileDecoder(nil), WithFlags(flags), WithoutSurvey)
+	configtest.AssertLoaded(t, c, testSetterConfiguration{Host: "example.com", Endpoint: testEndpoint{Host: "localhost", Port: 8080}})
+
+	c = testSetterConfiguration{}
+	err := Load("", &c, WithFileDecoder(nil), WithFlags(configtest.Flags("-host", "a b")), WithoutSurvey)
+
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.Field != "Host" {
+		t.Fatalf("expected a conversion error of Host but got: %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// KVEvent describes a change of a key on a `KVSource`.
+type KVEvent struct {
+	Key   string
+	Value string
+	// Deleted reports whether the key was removed, the Value is empty then.
+	Deleted bool
+}
+
+// KVSource is the interface which should be implemented by the
+// remote (or local) key/value stores in order to be used as a configuration source.
+//
+// See `WithKV`, `NewMemoryKV`, `NewFileKV`, `NewConsulKV` and `NewEtcdKV`.
+type KVSource interface {
+	// Get returns the value of a key and reports whether it exists.
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	// List returns the keys, and their values, that start with the "prefix".
+	List(ctx context.Context, prefix string) (map[string]string, error)
+	// Watch sends the changes of the keys that start with the "prefix"
+	// until the context is canceled, then the channel is closed.
+	Watch(ctx context.Context, prefix string) (<-chan KVEvent, error)
+}
+
+// WithKV enables the config to be loaded from a key/value store.
+// The keys are the fields' names under the "prefix", separated by slashes, i.e
+// "myapp/DBCredentials/Host" for the "DBCredentials.Host" field and "myapp" prefix.
+//
+// It scans the store right after the file decoder, so the store's values override the file's ones,
+// the file, flags and survey are still used for the rest of the fields.
+func WithKV(src KVSource, prefix string) Option {
+	return func(o *options) {
+		o.kv = src
+		o.kvPrefix = prefix
+	}
+}
+
+// TryLoadKV tries to load the "dest" configuration from a key/value store,
+// see `WithKV` for the keys.
+func TryLoadKV(ctx context.Context, src KVSource, prefix string, dest interface{}) error {
+	if !ok(dest) {
+		return ErrBad
+	}
+
+	return tryLoadKV(ctx, src, prefix, dest, defaultNaming, nil)
+}
+
+func tryLoadKV(ctx context.Context, src KVSource, prefix string, dest interface{}, n naming, provided presence) error {
+	prefix = kvPrefix(prefix)
+	values, err := src.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	walkFields(reflect.ValueOf(dest).Elem(), "", n, func(f field, fValue reflect.Value) {
+		if !f.Required || isSectionList(fValue.Type()) {
+			return
+		}
+
+		if got, ok := values[kvKey(prefix, f)]; ok {
+			if err := assignString(fValue, f, got); err != nil {
+				errs = append(errs, &ConversionError{Field: f.Name, Source: SourceKV, Key: kvKey(prefix, f), Err: err})
+				return
+			}
+
+			provided.add(f.Name)
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+func kvPrefix(prefix string) string {
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		prefix += "/"
+	}
+
+	return prefix
+}
+
+var kvReplacer = strings.NewReplacer(".", "/", "[", "/", "]", "")
+
+// kvKey returns the key of a field, i.e myapp/DBCredentials/Host or myapp/Upstreams/0/Host.
+func kvKey(prefix string, f field) string {
+	return prefix + kvReplacer.Replace(f.Name)
+}
+
+// MemoryKV is an in-memory `KVSource`, it's safe for concurrent use.
+// It's mostly useful for tests and local development.
+type MemoryKV struct {
+	mu       sync.RWMutex
+	values   map[string]string
+	watchers map[chan KVEvent]string // channel: prefix.
+}
+
+var _ KVSource = (*MemoryKV)(nil)
+
+// NewMemoryKV returns a new in-memory `KVSource` with the "values", it can be nil.
+func NewMemoryKV(values map[string]string) *MemoryKV {
+	kv := &MemoryKV{
+		values:   make(map[string]string, len(values)),
+		watchers: make(map[chan KVEvent]string),
+	}
+
+	for key, value := range values {
+		kv.values[key] = value
+	}
+
+	return kv
+}
+
+// Get implements the `KVSource` interface.
+func (kv *MemoryKV) Get(ctx context.Context, key string) (string, bool, error) {
+	kv.mu.RLock()
+	value, ok := kv.values[key]
+	kv.mu.RUnlock()
+	return value, ok, nil
+}
+
+// List implements the `KVSource` interface.
+func (kv *MemoryKV) List(ctx context.Context, prefix string) (map[string]string, error) {
+	kv.mu.RLock()
+	values := listPrefix(kv.values, prefix)
+	kv.mu.RUnlock()
+	return values, nil
+}
+
+func listPrefix(values map[string]string, prefix string) map[string]string {
+	list := make(map[string]string)
+	for key, value := range values {
+		if strings.HasPrefix(key, prefix) {
+			list[key] = value
+		}
+	}
+
+	return list
+}
+
+// Watch implements the `KVSource` interface.
+// Events are dropped if the receiver is not ready to receive them.
+func (kv *MemoryKV) Watch(ctx context.Context, prefix string) (<-chan KVEvent, error) {
+	ch := make(chan KVEvent, 16)
+
+	kv.mu.Lock()
+	kv.watchers[ch] = prefix
+	kv.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		kv.mu.Lock()
+		delete(kv.watchers, ch)
+		close(ch)
+		kv.mu.Unlock()
+	}()
+
+	return ch, nil
+}
+
+// Set sets a key's value and notifies the watchers.
+func (kv *MemoryKV) Set(key, value string) {
+	kv.mu.Lock()
+	kv.values[key] = value
+	kv.notify(KVEvent{Key: key, Value: value})
+	kv.mu.Unlock()
+}
+
+// Delete removes a key and notifies the watchers.
+func (kv *MemoryKV) Delete(key string) {
+	kv.mu.Lock()
+	if _, ok := kv.values[key]; ok {
+		delete(kv.values, key)
+		kv.notify(KVEvent{Key: key, Deleted: true})
+	}
+	kv.mu.Unlock()
+}
+
+func (kv *MemoryKV) notify(evt KVEvent) {
+	for ch, prefix := range kv.watchers {
+		if !strings.HasPrefix(evt.Key, prefix) {
+			continue
+		}
+
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+}
+
+// FileKV is a `KVSource` which is backed by a local file of a flat YAML (or JSON) object,
+// i.e `myapp/Addr: ":8080"`. The file is read on each call, so it can be edited while the program is running.
+// A missing file is an empty store.
+type FileKV struct {
+	Path string
+	// Interval is the period that the file's modification time is checked on `Watch`.
+	// Defaults to one second.
+	Interval time.Duration
+}
+
+var _ KVSource = (*FileKV)(nil)
+
+// NewFileKV returns a new file-backed `KVSource`.
+func NewFileKV(path string) *FileKV {
+	return &FileKV{Path: path, Interval: time.Second}
+}
+
+func (kv *FileKV) read() (map[string]string, error) {
+	data, err := ioutil.ReadFile(kv.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Get implements the `KVSource` interface.
+func (kv *FileKV) Get(ctx context.Context, key string) (string, bool, error) {
+	values, err := kv.read()
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := values[key]
+	return value, ok, nil
+}
+
+// List implements the `KVSource` interface.
+func (kv *FileKV) List(ctx context.Context, prefix string) (map[string]string, error) {
+	values, err := kv.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return listPrefix(values, prefix), nil
+}
+
+// Watch implements the `KVSource` interface.
+// It checks the file's modification time every `Interval` and sends the changed keys.
+func (kv *FileKV) Watch(ctx context.Context, prefix string) (<-chan KVEvent, error) {
+	prev, err := kv.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := kv.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ch := make(chan KVEvent)
+	go func() {
+		defer close(ch)
+
+		var modTime time.Time
+		if info, err := os.Stat(kv.Path); err == nil {
+			modTime = info.ModTime()
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			var cur time.Time
+			if info, err := os.Stat(kv.Path); err == nil {
+				cur = info.ModTime()
+			}
+
+			if cur.Equal(modTime) {
+				continue
+			}
+			modTime = cur
+
+			next, err := kv.List(ctx, prefix)
+			if err != nil {
+				continue // i.e the file is being written, try on the next tick.
+			}
+
+			for _, evt := range diffKV(prev, next) {
+				select {
+				case ch <- evt:
+				case <-ctx.Done():
+					return
+				}
+			}
+			prev = next
+		}
+	}()
+
+	return ch, nil
+}
+
+// diffKV returns the events that convert the "prev" values to the "next" ones, sorted by key.
+func diffKV(prev, next map[string]string) (events []KVEvent) {
+	for key, value := range next {
+		if old, ok := prev[key]; !ok || old != value {
+			events = append(events, KVEvent{Key: key, Value: value})
+		}
+	}
+
+	for key := range prev {
+		if _, ok := next[key]; !ok {
+			events = append(events, KVEvent{Key: key, Deleted: true})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Key < events[j].Key
+	})
+
+	return
+}
@@ -0,0 +1,358 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KVTimeout is the maximum duration of a key/value store's request,
+// when the caller's context has no deadline. It's not applied on watch requests.
+var KVTimeout = 10 * time.Second
+
+// KVWatchBackoff is the delay, plus a random jitter up to the same duration,
+// before a watch request which follows a failed one or one without a new index.
+var KVWatchBackoff = time.Second
+
+// waitKV sleeps for the `KVWatchBackoff` and its jitter or until the context is canceled.
+func waitKV(ctx context.Context) {
+	d := KVWatchBackoff + time.Duration(rand.Int63n(int64(KVWatchBackoff)+1))
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+}
+
+func withKVTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, KVTimeout)
+}
+
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+
+	return client
+}
+
+func doKV(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := httpClient(client).Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, bytes.TrimSpace(body))
+	}
+
+	return resp, nil
+}
+
+// ConsulKV is a `KVSource` of the Consul's key/value HTTP API.
+type ConsulKV struct {
+	// Addr is the agent's address, i.e "http://127.0.0.1:8500".
+	Addr string
+	// Token is the optional ACL token.
+	Token string
+	// Client defaults to the `http.DefaultClient`.
+	Client *http.Client
+}
+
+var _ KVSource = (*ConsulKV)(nil)
+
+// NewConsulKV returns a new `KVSource` of a Consul agent, i.e "http://127.0.0.1:8500".
+func NewConsulKV(addr string) *ConsulKV {
+	return &ConsulKV{Addr: strings.TrimSuffix(addr, "/")}
+}
+
+type consulPair struct {
+	Key   string
+	Value []byte // base64 on the wire, null for folders.
+}
+
+// list sends a recursive (or not) read of a key, the "index" is the blocking query's index, if any.
+func (kv *ConsulKV) list(ctx context.Context, key string, recurse bool, index string) (map[string]string, string, error) {
+	query := url.Values{}
+	if recurse {
+		query.Set("recurse", "true")
+	}
+
+	if index != "" {
+		query.Set("index", index)
+		query.Set("wait", "1m")
+	}
+
+	u := kv.Addr + "/v1/kv/" + (&url.URL{Path: key}).EscapedPath() + "?" + query.Encode()
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if kv.Token != "" {
+		req.Header.Set("X-Consul-Token", kv.Token)
+	}
+
+	resp, err := doKV(ctx, kv.Client, req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	index = resp.Header.Get("X-Consul-Index")
+	values := make(map[string]string)
+	if resp.StatusCode == http.StatusNotFound {
+		return values, index, nil
+	}
+
+	var pairs []consulPair
+	if err = json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, "", err
+	}
+
+	for _, pair := range pairs {
+		if pair.Value != nil {
+			values[pair.Key] = string(pair.Value)
+		}
+	}
+
+	return values, index, nil
+}
+
+// Get implements the `KVSource` interface.
+func (kv *ConsulKV) Get(ctx context.Context, key string) (string, bool, error) {
+	ctx, cancel := withKVTimeout(ctx)
+	defer cancel()
+
+	values, _, err := kv.list(ctx, key, false, "")
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := values[key]
+	return value, ok, nil
+}
+
+// List implements the `KVSource` interface.
+func (kv *ConsulKV) List(ctx context.Context, prefix string) (map[string]string, error) {
+	ctx, cancel := withKVTimeout(ctx)
+	defer cancel()
+
+	values, _, err := kv.list(ctx, prefix, true, "")
+	return values, err
+}
+
+// Watch implements the `KVSource` interface through Consul's blocking queries.
+// Failed requests and the ones without a new index are retried after the `KVWatchBackoff`,
+// a missing or decreased index, i.e of a restored store, starts the queries over.
+func (kv *ConsulKV) Watch(ctx context.Context, prefix string) (<-chan KVEvent, error) {
+	prev, index, err := kv.list(ctx, prefix, true, "")
+	if err != nil {
+		return nil, err
+	}
+
+	last, ok := consulIndex(index)
+	if !ok {
+		index = ""
+	}
+
+	ch := make(chan KVEvent)
+	go func() {
+		defer close(ch)
+
+		for ctx.Err() == nil {
+			next, nextIndex, err := kv.list(ctx, prefix, true, index)
+			if err != nil {
+				waitKV(ctx)
+				continue
+			}
+
+			current, ok := consulIndex(nextIndex)
+			switch {
+			case !ok || current < last:
+				// the next query is not a blocking one, the values are compared below.
+				index, last = "", 0
+				waitKV(ctx)
+			case current == last:
+				// timed out without changes, or the server does not block.
+				waitKV(ctx)
+				continue
+			default:
+				index, last = nextIndex, current
+			}
+
+			for _, evt := range diffKV(prev, next) {
+				select {
+				case ch <- evt:
+				case <-ctx.Done():
+					return
+				}
+			}
+			prev = next
+		}
+	}()
+
+	return ch, nil
+}
+
+// consulIndex parses the X-Consul-Index header, it reports false if it's missing or zero.
+func consulIndex(index string) (uint64, bool) {
+	n, err := strconv.ParseUint(index, 10, 64)
+	return n, err == nil && n > 0
+}
+
+// EtcdKV is a `KVSource` of the etcd's v3 JSON (gRPC gateway) HTTP API.
+type EtcdKV struct {
+	// Addr is the server's address, i.e "http://127.0.0.1:2379".
+	Addr string
+	// Client defaults to the `http.DefaultClient`.
+	Client *http.Client
+}
+
+var _ KVSource = (*EtcdKV)(nil)
+
+// NewEtcdKV returns a new `KVSource` of an etcd server, i.e "http://127.0.0.1:2379".
+func NewEtcdKV(addr string) *EtcdKV {
+	return &EtcdKV{Addr: strings.TrimSuffix(addr, "/")}
+}
+
+type etcdKeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type etcdRange struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+}
+
+// etcdPrefixRange returns the range of all the keys that start with the "prefix".
+func etcdPrefixRange(prefix string) etcdRange {
+	if prefix == "" {
+		return etcdRange{Key: []byte{0}, RangeEnd: []byte{0}}
+	}
+
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return etcdRange{Key: []byte(prefix), RangeEnd: end[:i+1]}
+		}
+	}
+
+	// all bytes are 0xff, read until the end.
+	return etcdRange{Key: []byte(prefix), RangeEnd: []byte{0}}
+}
+
+func (kv *EtcdKV) post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, kv.Addr+path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return doKV(ctx, kv.Client, req)
+}
+
+func (kv *EtcdKV) rangeValues(ctx context.Context, r etcdRange) (map[string]string, error) {
+	ctx, cancel := withKVTimeout(ctx)
+	defer cancel()
+
+	resp, err := kv.post(ctx, "/v3/kv/range", r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Kvs []etcdKeyValue `json:"kvs"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(result.Kvs))
+	for _, pair := range result.Kvs {
+		values[string(pair.Key)] = string(pair.Value)
+	}
+
+	return values, nil
+}
+
+// Get implements the `KVSource` interface.
+func (kv *EtcdKV) Get(ctx context.Context, key string) (string, bool, error) {
+	values, err := kv.rangeValues(ctx, etcdRange{Key: []byte(key)})
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := values[key]
+	return value, ok, nil
+}
+
+// List implements the `KVSource` interface.
+func (kv *EtcdKV) List(ctx context.Context, prefix string) (map[string]string, error) {
+	return kv.rangeValues(ctx, etcdPrefixRange(prefix))
+}
+
+// Watch implements the `KVSource` interface through the etcd's streaming watch request.
+func (kv *EtcdKV) Watch(ctx context.Context, prefix string) (<-chan KVEvent, error) {
+	resp, err := kv.post(ctx, "/v3/watch", map[string]interface{}{
+		"create_request": etcdPrefixRange(prefix),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan KVEvent)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var msg struct {
+				Result struct {
+					Events []struct {
+						Type string       `json:"type"`
+						Kv   etcdKeyValue `json:"kv"`
+					} `json:"events"`
+				} `json:"result"`
+			}
+
+			if err := dec.Decode(&msg); err != nil {
+				return // canceled or closed by the server.
+			}
+
+			for _, e := range msg.Result.Events {
+				evt := KVEvent{Key: string(e.Kv.Key), Value: string(e.Kv.Value), Deleted: e.Type == "DELETE"}
+				select {
+				case ch <- evt:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
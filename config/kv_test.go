@@ -0,0 +1,152 @@
+package config_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+func TestLoadKV(t *testing.T) {
+	kv := NewMemoryKV(map[string]string{
+		"myapp/Addr":    ":9090",
+		"myapp/DB/Host": "db.local",
+		"other/Year":    "2019",
+	})
+
+	fsys := configtest.FS(map[string]string{"config.yml": "Addr: :8080\nYear: 2017"})
+
+	var c testConfiguration
+	report := configtest.Load(t, "config.yml", &c, WithFS(fsys), WithKV(kv, "myapp/"), WithoutSurvey)
+
+	configtest.AssertLoaded(t, c, testConfiguration{Addr: ":9090", Year: 2017, DB: testDBCredentials{Host: "db.local"}})
+	configtest.AssertSource(t, report, "Addr", "kv")
+	configtest.AssertSource(t, report, "Year", "file")
+}
+
+func TestMemoryKVWatch(t *testing.T) {
+	kv := NewMemoryKV(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := kv.Watch(ctx, "myapp/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kv.Set("other/Addr", ":80")
+	kv.Set("myapp/Addr", ":8080")
+	kv.Delete("myapp/Addr")
+
+	for _, expected := range []KVEvent{{Key: "myapp/Addr", Value: ":8080"}, {Key: "myapp/Addr", Deleted: true}} {
+		select {
+		case got := <-events:
+			if expected != got {
+				t.Fatalf("expected event %#+v but got: %#+v", expected, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout while waiting for %#+v", expected)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestConsulKV(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/myapp/" || r.URL.Query().Get("recurse") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"Key": "myapp/", "Value": nil},
+			{"Key": "myapp/Addr", "Value": base64.StdEncoding.EncodeToString([]byte(":8080"))},
+		})
+	}))
+	defer srv.Close()
+
+	values, err := NewConsulKV(srv.URL).List(context.Background(), "myapp/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := 1, len(values); expected != got || values["myapp/Addr"] != ":8080" {
+		t.Fatalf("expected a single myapp/Addr key but got: %v", values)
+	}
+
+	if _, ok, err := NewConsulKV(srv.URL).Get(context.Background(), "missing"); ok || err != nil {
+		t.Fatalf("expected a missing key without error but got: %v", err)
+	}
+}
+
+func TestConsulKVWatchBackoff(t *testing.T) {
+	backoff := KVWatchBackoff
+	KVWatchBackoff = 20 * time.Millisecond
+	defer func() { KVWatchBackoff = backoff }()
+
+	// servers which return immediately, without an index or with the same one.
+	for _, index := range []string{"", "7"} {
+		var requests int64
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&requests, 1)
+			if index != "" {
+				w.Header().Set("X-Consul-Index", index)
+			}
+			w.Write([]byte("[]"))
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		events, err := NewConsulKV(srv.URL).Watch(ctx, "myapp/")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for range events {
+		}
+		cancel()
+		srv.Close()
+
+		// a request every 20-40ms at most, plus the first list.
+		if got := atomic.LoadInt64(&requests); got > 11 {
+			t.Fatalf("index %q: expected the watch requests to back off but got %d requests", index, got)
+		}
+	}
+}
+
+func TestEtcdKV(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Key      []byte `json:"key"`
+			RangeEnd []byte `json:"range_end"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if r.URL.Path != "/v3/kv/range" || string(req.Key) != "myapp/" || string(req.RangeEnd) != "myapp0" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"kvs": []map[string][]byte{{"key": []byte("myapp/Addr"), "value": []byte(":8080")}},
+		})
+	}))
+	defer srv.Close()
+
+	values, err := NewEtcdKV(srv.URL).List(context.Background(), "myapp/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := ":8080", values["myapp/Addr"]; expected != got {
+		t.Fatalf("expected myapp/Addr to be %s but got: %s", expected, got)
+	}
+}
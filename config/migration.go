@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// VersionKey is the key of the configuration file's version,
+// i.e "version: 2", which is used to decide which `Migrations` should run.
+// A file without it is version 0.
+var VersionKey = "version"
+
+// Migration upgrades a raw decoded configuration file from its previous version,
+// i.e by renaming or moving its keys. Objects are always map[string]interface{} values.
+type Migration func(raw map[string]interface{}) error
+
+// Migrations is a registry of configuration file migrations,
+// each one upgrades the file to its version, see `WithMigrations`.
+type Migrations struct {
+	funcs map[int]Migration
+}
+
+// NewMigrations returns a new empty `Migrations` registry.
+func NewMigrations() *Migrations {
+	return &Migrations{funcs: make(map[int]Migration)}
+}
+
+// Register adds a migration which upgrades a file from the previous version to the "version".
+// Registering the same version twice replaces the previous one.
+// Returns itself in order to be chained.
+func (m *Migrations) Register(version int, migrate Migration) *Migrations {
+	m.funcs[version] = migrate
+	return m
+}
+
+// Latest returns the newest registered version.
+func (m *Migrations) Latest() (latest int) {
+	for version := range m.funcs {
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return
+}
+
+// Migrate runs the registered migrations that are newer than the "raw" file's version,
+// in order, and sets its version to the latest one.
+// Returns the file's version before and after the migrations.
+func (m *Migrations) Migrate(raw map[string]interface{}) (from, to int, err error) {
+	from, err = rawVersion(raw)
+	if err != nil {
+		return
+	}
+
+	to = m.Latest()
+	if from > to {
+		return from, from, fmt.Errorf("configuration version %d is newer than the supported %d", from, to)
+	}
+
+	versions := make([]int, 0, len(m.funcs))
+	for version := range m.funcs {
+		if version > from {
+			versions = append(versions, version)
+		}
+	}
+	sort.Ints(versions)
+
+	for _, version := range versions {
+		if err = m.funcs[version](raw); err != nil {
+			return from, to, fmt.Errorf("migrate configuration to version %d: %w", version, err)
+		}
+	}
+
+	if to > from {
+		raw[VersionKey] = to
+	}
+
+	return
+}
+
+func rawVersion(raw map[string]interface{}) (int, error) {
+	switch v := raw[VersionKey].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	case int64: // toml integers.
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("invalid configuration version: %v", v)
+	}
+}
+
+// WithMigrations runs the "migrations" on the configuration file's contents, before they are decoded to the "dest".
+// The file should be of a registered format, see `WithFormat`.
+//
+// See `WithMigrationsWriteBack` too.
+func WithMigrations(migrations *Migrations) Option {
+	return func(o *options) {
+		o.migrations = migrations
+	}
+}
+
+// WithMigrationsWriteBack makes `Load` to write the upgraded contents back to the configuration file,
+// when migrations did run. Note that the file's comments and keys order are not kept.
+// Has no effect on `LoadReader` and `LoadFS`.
+func WithMigrationsWriteBack(o *options) {
+	o.migrationsWriteBack = true
+}
+
+// migrate returns the upgraded file's contents, or the "data" themselves if nothing changed.
+func migrate(data []byte, filename string, opts options) ([]byte, error) {
+	raw, err := decodeRaw(data, opts.format)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := opts.migrations.Migrate(raw)
+	if err != nil || from == to {
+		return data, err
+	}
+
+	marshal, ok := encoders[normalizeFormat(opts.format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown configuration format: %q", opts.format)
+	}
+
+	upgraded, err := marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.logf("configuration migrated from version %d to %d", from, to)
+
+	if opts.migrationsWriteBack && filename != "" && opts.fsys == nil {
+		mode := os.FileMode(0644)
+		if info, err := os.Stat(filename); err == nil {
+			mode = info.Mode()
+		}
+
+		if err = ioutil.WriteFile(filename, upgraded, mode); err != nil {
+			return nil, err
+		}
+	}
+
+	return upgraded, nil
+}
+
+// warnDeprecated reports the fields, tagged as `deprecated:"use NewName"`, that are set by the file.
+// The file's keys are resolved as its decoder does, see `walkRawFields`.
+func warnDeprecated(data []byte, dest interface{}, opts options) {
+	hasDeprecated := false
+	for _, f := range lookupFields(reflectType(dest), field{}, opts.naming) {
+		if f.Deprecated != "" {
+			hasDeprecated = true
+			break
+		}
+	}
+
+	if !hasDeprecated {
+		return
+	}
+
+	raw, err := decodeRaw(data, opts.format)
+	if err != nil {
+		return
+	}
+
+	walkRawFields(raw, reflect.ValueOf(dest).Elem(), "", opts.naming, fileNaming(opts.format), func(f field, _ reflect.Value, _ interface{}, _ func(interface{})) {
+		if f.Deprecated != "" {
+			opts.warnf("%s is deprecated: %s", f.Name, f.Deprecated)
+		}
+	})
+}
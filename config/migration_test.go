@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+type testMigrationConfiguration struct {
+	Version    int               `yaml:"version"`
+	Addr       string            `yaml:"Addr"`
+	ServerName string            `yaml:"ServerName" deprecated:"use Name"`
+	Name       string            `yaml:"Name"`
+	DB         testDBCredentials `yaml:"DB"`
+}
+
+func testMigrations() *Migrations {
+	return NewMigrations().
+		Register(1, func(raw map[string]interface{}) error {
+			// v1: the "Host" moved to the "DB" section.
+			raw["DB"] = map[string]interface{}{"Host": raw["Host"]}
+			delete(raw, "Host")
+			return nil
+		}).
+		Register(2, func(raw map[string]interface{}) error {
+			// v2: the "Port" is part of the "Addr".
+			raw["Addr"] = ":" + raw["Port"].(string)
+			delete(raw, "Port")
+			return nil
+		})
+}
+
+func TestLoadMigrations(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(filename, []byte("Host: localhost\nPort: \"8080\"\nServerName: iris\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var c testMigrationConfiguration
+	report := configtest.Load(t, filename, &c, WithMigrations(testMigrations()), WithMigrationsWriteBack, WithLogger(nil), WithoutSurvey)
+
+	configtest.AssertLoaded(t, c, testMigrationConfiguration{
+		Version:    2,
+		Addr:       ":8080",
+		ServerName: "iris",
+		DB:         testDBCredentials{Host: "localhost"},
+	})
+
+	if expected, got := []string{"ServerName is deprecated: use Name"}, report.Warnings; len(got) != 1 || expected[0] != got[0] {
+		t.Fatalf("expected warnings %v but got: %v", expected, got)
+	}
+
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(contents), "version: 2") {
+		t.Fatalf("expected the upgraded file to be written back but got:\n%s", contents)
+	}
+
+	// an upgraded file is not migrated again.
+	c = testMigrationConfiguration{}
+	configtest.Load(t, filename, &c, WithMigrations(testMigrations()), WithLogger(nil), WithoutSurvey)
+	if expected, got := ":8080", c.Addr; expected != got {
+		t.Fatalf("expected Addr to be %s but got: %s", expected, got)
+	}
+}
+
+func TestLoadDeprecatedNaming(t *testing.T) {
+	type namingConfiguration struct {
+		ServerName string `deprecated:"use Name"`
+		Name       string
+	}
+
+	var (
+		c      namingConfiguration
+		report Report
+	)
+	err := LoadReader(strings.NewReader("servername: iris\n"), "yaml", &c, WithNaming(SnakeCase), WithReport(&report), WithoutSurvey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := []string{"server_name is deprecated: use Name"}, report.Warnings; len(got) != 1 || expected[0] != got[0] {
+		t.Fatalf("expected warnings %v but got: %v", expected, got)
+	}
+}
+
+func TestLoadMigrationsNewerVersion(t *testing.T) {
+	var c testMigrationConfiguration
+	err := LoadReader(strings.NewReader(`{"version": 3}`), "json", &c, WithMigrations(testMigrations()), WithoutSurvey)
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected an error for a newer configuration version but got: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy converts a Go field's name to a configuration's name,
+// i.e "MaxConns" to "max_conns".
+// It's applied to the fields that their name is not customized by the file decoder's tag.
+//
+// See `WithNaming`.
+type NamingStrategy func(name string) string
+
+// The built-in naming strategies.
+var (
+	// SnakeCase converts "MaxConns" to "max_conns".
+	SnakeCase NamingStrategy = func(name string) string {
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	}
+	// KebabCase converts "MaxConns" to "max-conns".
+	KebabCase NamingStrategy = func(name string) string {
+		return strings.ToLower(strings.Join(splitWords(name), "-"))
+	}
+	// CamelCase converts "MaxConns" to "maxConns" and "HTTPAddr" to "httpAddr".
+	CamelCase NamingStrategy = func(name string) string {
+		words := splitWords(name)
+		for i, w := range words {
+			if i == 0 {
+				words[i] = strings.ToLower(w)
+				continue
+			}
+
+			words[i] = strings.ToUpper(w[:1]) + strings.ToLower(w[1:])
+		}
+
+		return strings.Join(words, "")
+	}
+	// ScreamingSnakeCase converts "MaxConns" to "MAX_CONNS".
+	ScreamingSnakeCase NamingStrategy = func(name string) string {
+		return strings.ToUpper(strings.Join(splitWords(name), "_"))
+	}
+)
+
+// splitWords splits a Go identifier to its words, i.e "HTTPAddr" to "HTTP" and "Addr".
+func splitWords(name string) (words []string) {
+	runes := []rune(name)
+	start := 0
+
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+		if cur == '_' || cur == '-' {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+
+		if unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower)) {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i
+		}
+	}
+
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	return
+}
+
+// WithNaming sets the strategy which converts the Go fields' names
+// to the names that are used on flags, environment variables, prompts and error messages,
+// i.e `SnakeCase`. Fields with a name on their file decoder's tag, i.e `yaml:"addr"`, keep that name.
+//
+// Defaults to nil, the Go field's name is used as it's.
+func WithNaming(strategy NamingStrategy) Option {
+	return func(o *options) {
+		o.naming.strategy = strategy
+	}
+}
+
+// naming holds the rules that a field's name is resolved.
+type naming struct {
+	// the file decoder's tag key, i.e "yaml".
+	tag string
+	// if not nil then it's applied to the fields without a name on their tag.
+	strategy NamingStrategy
+}
+
+// defaultNaming is used where there are no options.
+var defaultNaming = naming{tag: "yaml"}
+
+// namingFor returns the naming of a file format, i.e "json" uses the "json" tag.
+func namingFor(format string, strategy NamingStrategy) naming {
+	tag := normalizeFormat(format)
+	switch tag {
+	case "yml":
+		tag = "yaml"
+	case "env":
+		tag = "yaml" // the env format's names are the ones of the `WithEnv`.
+	}
+
+	return naming{tag: tag, strategy: strategy}
+}
+
+// fileNaming returns the naming of a file format's keys, as its decoder resolves them,
+// i.e the yaml decoder lowercases the names of the untagged fields.
+func fileNaming(format string) naming {
+	n := namingFor(format, nil)
+	if n.tag == "yaml" {
+		n.strategy = strings.ToLower
+	}
+
+	return n
+}
+
+// fieldName returns the name of a struct field;
+// the name of its decoder's tag, if any, otherwise its Go name converted by the strategy.
+func (n naming) fieldName(f reflect.StructField) string {
+	if n.tag != "" {
+		if name := strings.Split(f.Tag.Get(n.tag), ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+
+	if n.strategy != nil {
+		return n.strategy(f.Name)
+	}
+
+	return f.Name
+}
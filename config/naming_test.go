@@ -0,0 +1,162 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	tests := []struct {
+		strategy NamingStrategy
+		name     string
+		expected string
+	}{
+		{SnakeCase, "MaxConns", "max_conns"},
+		{SnakeCase, "HTTPAddr", "http_addr"},
+		{SnakeCase, "TLSCert", "tls_cert"},
+		{KebabCase, "DBName", "db-name"},
+		{KebabCase, "Port2FA", "port2-fa"},
+		{CamelCase, "HTTPAddr", "httpAddr"},
+		{CamelCase, "MaxConns", "maxConns"},
+		{ScreamingSnakeCase, "MaxConns", "MAX_CONNS"},
+		{ScreamingSnakeCase, "ID", "ID"},
+	}
+
+	for i, tt := range tests {
+		if got := tt.strategy(tt.name); tt.expected != got {
+			t.Fatalf("[%d] expected %s to be converted to %s but got: %s", i, tt.name, tt.expected, got)
+		}
+	}
+}
+
+type testNamingConfiguration struct {
+	Addr     string `json:"addr"`
+	MaxConns int
+	Database struct {
+		Host     string `json:"host"`
+		Password string `json:"-" config:"secret"`
+	} `json:"db"`
+}
+
+func TestLoadNaming(t *testing.T) {
+	os.Setenv("APP_DB_PASSWORD", "123")
+	defer os.Unsetenv("APP_DB_PASSWORD")
+
+	answers := configtest.Answers(map[string]string{"db.host": "localhost"})
+
+	var c testNamingConfiguration
+	report := configtest.LoadReader(t, strings.NewReader(`{"addr": ":8080"}`), "json", &c,
+		WithNaming(KebabCase),
+		WithFlags(configtest.Flags("-max-conns", "10")),
+		WithEnv("APP"),
+		WithPrompter(answers))
+
+	if expected, got := "localhost", c.Database.Host; expected != got {
+		t.Fatalf("expected db.host to be %s but got: %s", expected, got)
+	}
+
+	configtest.AssertSource(t, report, "addr", "file")
+	configtest.AssertSource(t, report, "max-conns", "flag")
+	configtest.AssertSource(t, report, "db.password", "env")
+	configtest.AssertSource(t, report, "db.host", "prompt")
+}
+
+func TestLoadNamingFlags(t *testing.T) {
+	var c testNamingConfiguration
+	report := configtest.LoadReader(t, strings.NewReader(`{"addr": ":8080"}`), "json", &c,
+		WithNaming(CamelCase),
+		WithFlags(configtest.Flags("-maxConns", "10")),
+		WithoutSurvey)
+
+	if expected, got := 10, c.MaxConns; expected != got {
+		t.Fatalf("expected maxConns to be %d but got: %d", expected, got)
+	}
+
+	configtest.AssertSource(t, report, "maxConns", "flag")
+}
+
+type testBaseConfiguration struct {
+	Addr  string `yaml:"addr"`
+	Debug bool   `yaml:"debug"`
+}
+
+type testDatabase struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+type testEmbeddedConfiguration struct {
+	testBaseConfiguration `yaml:",inline"`
+	Primary               testDatabase `yaml:"primary" prefix:"db_"`
+	Cache                 testDatabase `yaml:"cache" config:"squash"`
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": "addr: :8080\ndebug: true\nprimary:\n  host: db.local\n"})
+	t.Setenv("APP_HOST", "cache.local")
+	t.Setenv("APP_PORT", "6379")
+
+	var c testEmbeddedConfiguration
+	report := configtest.Load(t, "config.yml", &c, WithFS(fsys),
+		WithFlags(configtest.Flags("-db_port", "5432")), WithEnv("APP"), WithoutSurvey)
+
+	expected := testEmbeddedConfiguration{
+		testBaseConfiguration: testBaseConfiguration{Addr: ":8080", Debug: true},
+		Primary:               testDatabase{Host: "db.local", Port: 5432},
+		Cache:                 testDatabase{Host: "cache.local", Port: 6379},
+	}
+	configtest.AssertLoaded(t, c, expected)
+	configtest.AssertSource(t, report, "addr", "file")
+	configtest.AssertSource(t, report, "db_host", "file")
+	configtest.AssertSource(t, report, "db_port", "flag")
+	configtest.AssertSource(t, report, "host", "env")
+
+	// the template follows the file's structure, only the inline struct is flattened.
+	b, err := Template(c, "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "addr: :8080\ndebug: true\nprimary:\n  host: db.local\n"; !strings.HasPrefix(string(b), expected) {
+		t.Fatalf("expected template to start with:\n%s\nbut got:\n%s", expected, b)
+	}
+}
+
+func TestLoadEmbeddedExplicitZero(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": "addr: :8080\ndebug: false\nprimary:\n  host: db.local\n  port: 0\ncache:\n  host: cache.local\n  port: 0\n"})
+	answers := configtest.Answers(nil)
+
+	var c testEmbeddedConfiguration
+	configtest.Load(t, "config.yml", &c, WithFS(fsys), WithPrompter(answers))
+
+	// the inline, prefixed and squashed sections are nested or flattened as the decoder reads them.
+	if got := answers.Asked(); len(got) > 0 {
+		t.Fatalf("expected nothing to be asked but got: %v", got)
+	}
+}
+
+type testConflictConfiguration struct {
+	Host    string       `yaml:"host"`
+	Primary testDatabase `config:"squash"`
+	Replica testDatabase `config:"squash"`
+}
+
+func TestLoadEmbeddedConflict(t *testing.T) {
+	var c testConflictConfiguration
+	err := Load("", &c, WithFileDecoder(nil), WithoutSurvey)
+
+	var conflictErr *FieldConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("expected a field conflict error but got: %v", err)
+	}
+
+	// the Host of the struct shadows the embedded ones, as the Go promotion does.
+	if expected, got := "conflicting fields for port: Primary.Port, Replica.Port", err.Error(); expected != got {
+		t.Fatalf("expected error: %s but got: %s", expected, got)
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+type pflags struct {
+	set *pflag.FlagSet
+}
+
+// PFlags returns a `FlagSource` of a spf13/pflag flag set,
+// i.e pflag.CommandLine or the cobra command's Flags().
+func PFlags(set *pflag.FlagSet) FlagSource {
+	return pflags{set}
+}
+
+func (s pflags) Parsed() bool {
+	return s.set.Parsed()
+}
+
+func (s pflags) Parse(arguments []string) error {
+	return s.set.Parse(arguments)
+}
+
+func (s pflags) Lookup(name, shorthand string) (string, bool) {
+	arg := s.set.Lookup(name)
+	if arg == nil && shorthand != "" {
+		arg = s.set.ShorthandLookup(shorthand)
+	}
+
+	if arg == nil {
+		return "", false
+	}
+
+	if slice, ok := arg.Value.(pflag.SliceValue); ok {
+		return joinList(slice.GetSlice()), true
+	}
+
+	return arg.Value.String(), true
+}
+
+func (s pflags) Changed(name, shorthand string) bool {
+	arg := s.set.Lookup(name)
+	if arg == nil && shorthand != "" {
+		arg = s.set.ShorthandLookup(shorthand)
+	}
+
+	return arg != nil && arg.Changed
+}
+
+// BindPFlags same as `BindFlags` but for a spf13/pflag flag set,
+// the fields' "short" tag is used as the flags' shorthand.
+func BindPFlags(set *pflag.FlagSet, dest interface{}, optional ...Option) error {
+	n := newOptions(optional).naming
+	return bindFlags(dest, n, func(short string) { bindConfigPFlags(set, short) }, func(f field, fValue reflect.Value) {
+		bindPFlag(set, n, f, fValue)
+	})
+}
+
+func bindPFlag(set *pflag.FlagSet, n naming, f field, fValue reflect.Value) {
+	name := n.flagName(f)
+	if set.Lookup(name) != nil {
+		return
+	}
+
+	short := f.Short
+	if short != "" && set.ShorthandLookup(short) != nil {
+		short = "" // keep the long one at least.
+	}
+
+	if isCollection(fValue.Type()) {
+		set.VarP(newListFlag(f, fValue), name, short, f.Help)
+		return
+	}
+
+	if fValue.Kind() == reflect.Bool {
+		set.BoolP(name, short, fValue.Bool(), f.Help)
+		return
+	}
+
+	set.StringP(name, short, flagDefault(f, fValue), f.Help)
+}
+
+// Command is the interface which the spf13/cobra's `*cobra.Command` implements,
+// it's used to bind a configuration to a command without depending on cobra.
+type Command interface {
+	Flags() *pflag.FlagSet
+	PersistentFlags() *pflag.FlagSet
+}
+
+// BindCommand declares a flag to the "cmd" for each one of the "dest" configuration's fields,
+// see `BindPFlags`. Fields tagged as 'config:"persistent"' are declared to the command's
+// persistent flags, so they are available on its subcommands too, the rest are local to the command.
+// The `ConfigFlag` and `ConfigFormatFlag` are persistent flags.
+//
+// Use the `WithCommand` option to load them.
+func BindCommand(cmd Command, dest interface{}, optional ...Option) error {
+	n := newOptions(optional).naming
+	return bindFlags(dest, n, func(short string) { bindConfigPFlags(cmd.PersistentFlags(), short) }, func(f field, fValue reflect.Value) {
+		set := cmd.Flags()
+		if f.Persistent {
+			set = cmd.PersistentFlags()
+		}
+
+		bindPFlag(set, n, f, fValue)
+	})
+}
+
+// WithCommand enables the config to be loaded from a command's flags,
+// including the persistent flags of its parents. The command's flags should be parsed already,
+// i.e call `Load` inside a cobra command's Run function.
+func WithCommand(cmd Command) Option {
+	return WithFlagSource(PFlags(cmd.Flags()))
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"reflect"
+)
+
+// presence holds the names of the fields that were provided by a source,
+// i.e a `Debug: false` of the file or a "-debug=false" flag.
+// A provided field is not missing, even if its value is zero, so it's not asked or filled by the next sources.
+type presence map[string]struct{}
+
+func (p presence) add(name string) {
+	if p != nil {
+		p[name] = struct{}{}
+	}
+}
+
+func (p presence) has(name string) bool {
+	_, ok := p[name]
+	return ok
+}
+
+// addFile adds the fields which their keys exist in the file's contents, with a non-null value.
+// The keys are resolved as the file's decoder does, see `walkRawFields`.
+func (p presence) addFile(data []byte, dest interface{}, opts options) {
+	if p == nil || opts.format == "" {
+		return
+	}
+
+	raw, err := decodeRaw(data, opts.format)
+	if err != nil {
+		return
+	}
+
+	walkRawFields(raw, reflect.ValueOf(dest).Elem(), "", opts.naming, fileNaming(opts.format), func(f field, _ reflect.Value, value interface{}, _ func(interface{})) {
+		if value != nil {
+			p.add(f.Name)
+		}
+	})
+}
+
+func visitMissingFields(dest interface{}, n naming, provided presence, fn func(f field, fValue reflect.Value)) {
+	walkFields(reflect.ValueOf(dest).Elem(), "", n, func(f field, fValue reflect.Value) {
+		if !f.Required || provided.has(f.Name) {
+			return
+		}
+
+		if isZero(fValue) {
+			fn(f, fValue)
+		}
+	})
+}
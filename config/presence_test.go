@@ -0,0 +1,61 @@
+package config_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+func TestLoadExplicitZeroValues(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": "Addr: :8080\nDebug: false\nYear: 0\nDB:\n  Host: ~"})
+	answers := configtest.Answers(nil)
+
+	var c testConfiguration
+	configtest.Load(t, "config.yml", &c,
+		WithFS(fsys),
+		WithPrompter(answers),
+		WithFlags(configtest.Flags("-servername=", "-db.username=kataras")),
+	)
+
+	// Debug and Year are provided by the file and ServerName by the flag,
+	// a null value is not provided.
+	if expected, got := []string{"DB.Password", "DB.Host"}, answers.Asked(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected to be asked for %v but got: %v", expected, got)
+	}
+}
+
+func TestLoadExplicitZeroFlag(t *testing.T) {
+	answers := configtest.Answers(nil)
+
+	var c testConfiguration
+	configtest.Load(t, "config.yml", &c,
+		WithFileDecoder(nil),
+		WithPrompter(answers),
+		WithFlags(configtest.Flags("-addr=:8080", "-debug=false", "-year=0", "-servername=my server")),
+	)
+
+	if expected, got := []string{"DB.Username", "DB.Password", "DB.Host"}, answers.Asked(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected to be asked for %v but got: %v", expected, got)
+	}
+}
+
+func TestLoadExplicitZeroNaming(t *testing.T) {
+	type namingConfiguration struct {
+		Addr     string
+		MaxConns int
+	}
+
+	fsys := configtest.FS(map[string]string{"config.yml": "addr: :8080\nmaxconns: 0"})
+	answers := configtest.Answers(map[string]string{"max_conns": "7"})
+
+	var c namingConfiguration
+	configtest.Load(t, "config.yml", &c, WithFS(fsys), WithNaming(SnakeCase), WithPrompter(answers))
+
+	if got := answers.Asked(); len(got) > 0 {
+		t.Fatalf("expected nothing to be asked but got: %v", got)
+	}
+
+	configtest.AssertLoaded(t, c, namingConfiguration{Addr: ":8080"})
+}
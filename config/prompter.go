@@ -0,0 +1,291 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Question describes a single prompt for a configuration field's value,
+// it's passed to the `Prompter`'s methods.
+type Question struct {
+	// Name is the field's full name, i.e "DBCredentials.Password".
+	Name string
+	// Message is the text that should be shown to the user.
+	Message string
+	// Help is an optional, longer, description of the expected value.
+	Help string
+	// Default is the answer which is used when the user's answer is empty.
+	// For `Confirm` it's parsed as a boolean, i.e "true".
+	Default string
+	// Options are the available choices of a `Select`.
+	Options []string
+	// Validate, if not nil, reports whether an answer is acceptable,
+	// a non-nil error means that the question should be asked again.
+	Validate func(answer string) error
+}
+
+// Prompter is the interface which should be implemented by the
+// prompt backends that ask for the missing configuration fields' values.
+//
+// See `SurveyPrompter`, `NewStdinPrompter` and `NewScriptedPrompter`.
+type Prompter interface {
+	// Input asks for a single line of text.
+	Input(q Question) (string, error)
+	// Password asks for a secret text, it should not be echoed back if possible.
+	Password(q Question) (string, error)
+	// Confirm asks for a yes or no answer.
+	Confirm(q Question) (bool, error)
+	// Select asks to choose one of the "q.Options".
+	Select(q Question) (string, error)
+}
+
+// WithPrompter changes the backend which is used to ask for missing fields.
+// Has no effect when survey is disabled.
+//
+// Defaults to `SurveyPrompter`.
+func WithPrompter(p Prompter) Option {
+	return func(o *options) {
+		o.prompter = p
+	}
+}
+
+// StdinPrompter is a plain, line-based, `Prompter`.
+// Questions are written to an output and each answer is read as a single line,
+// so it works on terminals that survey does not support and on pipes.
+//
+// Note that passwords are echoed back, there is no terminal control at all.
+type StdinPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+var _ Prompter = (*StdinPrompter)(nil)
+
+// NewStdinPrompter returns a new line-based `Prompter` which reads from "in"
+// and writes to "out". If "in" is nil then `os.Stdin` is used, if "out" is nil
+// then `os.Stdout` is used instead.
+func NewStdinPrompter(in io.Reader, out io.Writer) *StdinPrompter {
+	if in == nil {
+		in = os.Stdin
+	}
+
+	if out == nil {
+		out = os.Stdout
+	}
+
+	return &StdinPrompter{in: bufio.NewReader(in), out: out}
+}
+
+// readLine reads the next line, an io.EOF is only reported when there is nothing left to read.
+func (p *StdinPrompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+func (p *StdinPrompter) ask(q Question, suffix string, parse func(line string) error) error {
+	for {
+		fmt.Fprintf(p.out, "%s%s: ", q.Message, suffix)
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+
+		if line == "" {
+			line = q.Default
+		}
+
+		if line == "?" && q.Help != "" {
+			fmt.Fprintln(p.out, q.Help)
+			continue
+		}
+
+		if err = parse(line); err != nil {
+			fmt.Fprintf(p.out, "invalid answer: %v\n", err)
+			continue
+		}
+
+		return nil
+	}
+}
+
+func validate(q Question, answer string) error {
+	if q.Validate == nil {
+		return nil
+	}
+
+	return q.Validate(answer)
+}
+
+// Input implements the `Prompter` interface.
+func (p *StdinPrompter) Input(q Question) (ans string, err error) {
+	suffix := ""
+	if q.Default != "" {
+		suffix = " [" + q.Default + "]"
+	}
+
+	err = p.ask(q, suffix, func(line string) error {
+		ans = line
+		return validate(q, line)
+	})
+	return
+}
+
+// Password implements the `Prompter` interface.
+func (p *StdinPrompter) Password(q Question) (ans string, err error) {
+	err = p.ask(q, "", func(line string) error {
+		ans = line
+		return validate(q, line)
+	})
+	return
+}
+
+// Confirm implements the `Prompter` interface.
+func (p *StdinPrompter) Confirm(q Question) (ans bool, err error) {
+	suffix := " (y/N)"
+	if def, _ := strconv.ParseBool(q.Default); def {
+		suffix = " (Y/n)"
+		q.Default = "y"
+	} else {
+		q.Default = "n"
+	}
+
+	err = p.ask(q, suffix, func(line string) (err error) {
+		ans, err = parseYesNo(line)
+		return
+	})
+	return
+}
+
+func parseYesNo(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	default:
+		return strconv.ParseBool(s)
+	}
+}
+
+// Select implements the `Prompter` interface.
+// The answer can be the option itself or its number, starting from 1.
+func (p *StdinPrompter) Select(q Question) (ans string, err error) {
+	for i, opt := range q.Options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, opt)
+	}
+
+	suffix := ""
+	if q.Default != "" {
+		suffix = " [" + q.Default + "]"
+	}
+
+	err = p.ask(q, suffix, func(line string) error {
+		if n, err := strconv.Atoi(line); err == nil && n > 0 && n <= len(q.Options) {
+			line = q.Options[n-1]
+		}
+
+		for _, opt := range q.Options {
+			if opt == line {
+				ans = opt
+				return validate(q, opt)
+			}
+		}
+
+		return fmt.Errorf("%q is not one of the available options", line)
+	})
+	return
+}
+
+// ErrNoAnswer is returned by the `ScriptedPrompter` when
+// there is no answer for a question's field name, the field is kept as it's.
+// Any other error of a `Prompter` fails the `Load`.
+var ErrNoAnswer = errors.New("no scripted answer")
+
+// ScriptedPrompter is a `Prompter` which answers from a map
+// of field names (see `Question.Name`) and their values, instead of asking.
+// It's mostly useful for tests and non-interactive environments.
+//
+// An empty answer results to the question's default value
+// and a confirmation's answer is parsed as a boolean (yes and no are accepted too).
+type ScriptedPrompter struct {
+	Answers map[string]string
+
+	asked []string
+}
+
+var _ Prompter = (*ScriptedPrompter)(nil)
+
+// NewScriptedPrompter returns a new `ScriptedPrompter` which answers from the "answers".
+func NewScriptedPrompter(answers map[string]string) *ScriptedPrompter {
+	return &ScriptedPrompter{Answers: answers}
+}
+
+// Asked returns the field names of the questions that were asked so far, in order.
+func (p *ScriptedPrompter) Asked() []string {
+	return p.asked
+}
+
+func (p *ScriptedPrompter) answer(q Question) (string, error) {
+	p.asked = append(p.asked, q.Name)
+
+	ans, ok := p.Answers[q.Name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNoAnswer, q.Name)
+	}
+
+	if ans == "" {
+		ans = q.Default
+	}
+
+	return ans, nil
+}
+
+// Input implements the `Prompter` interface.
+func (p *ScriptedPrompter) Input(q Question) (string, error) {
+	ans, err := p.answer(q)
+	if err != nil {
+		return "", err
+	}
+
+	return ans, validate(q, ans)
+}
+
+// Password implements the `Prompter` interface.
+func (p *ScriptedPrompter) Password(q Question) (string, error) {
+	return p.Input(q)
+}
+
+// Confirm implements the `Prompter` interface.
+func (p *ScriptedPrompter) Confirm(q Question) (bool, error) {
+	ans, err := p.answer(q)
+	if err != nil {
+		return false, err
+	}
+
+	return parseYesNo(ans)
+}
+
+// Select implements the `Prompter` interface.
+func (p *ScriptedPrompter) Select(q Question) (string, error) {
+	ans, err := p.answer(q)
+	if err != nil {
+		return "", err
+	}
+
+	for _, opt := range q.Options {
+		if opt == ans {
+			return ans, validate(q, ans)
+		}
+	}
+
+	return "", fmt.Errorf("%q is not one of the available options of %s", ans, q.Name)
+}
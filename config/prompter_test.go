@@ -0,0 +1,138 @@
+package config_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+func TestStdinPrompter(t *testing.T) {
+	in := strings.NewReader("abc\n42\n\nmaybe\ny\n2\n")
+	out := new(bytes.Buffer)
+	p := NewStdinPrompter(in, out)
+
+	q := Question{Name: "Year", Message: "Year", Validate: func(ans string) error {
+		if ans != "42" {
+			return errors.New("not the answer")
+		}
+		return nil
+	}}
+
+	if ans, err := p.Input(q); err != nil || ans != "42" {
+		t.Fatalf("expected the second line to be accepted but got: %q (%v)", ans, err)
+	}
+
+	if ans, err := p.Input(Question{Name: "Addr", Message: "Addr", Default: ":8080"}); err != nil || ans != ":8080" {
+		t.Fatalf("expected the default value on empty line but got: %q (%v)", ans, err)
+	}
+
+	if ans, err := p.Confirm(Question{Name: "Debug", Message: "Debug?"}); err != nil || !ans {
+		t.Fatalf("expected a confirmation after an invalid answer but got: %v (%v)", ans, err)
+	}
+
+	if ans, err := p.Select(Question{Name: "Level", Message: "Level", Options: []string{"info", "debug"}}); err != nil || ans != "debug" {
+		t.Fatalf("expected the second option but got: %q (%v)", ans, err)
+	}
+
+	if expected, got := 2, strings.Count(out.String(), "invalid answer"); expected != got {
+		t.Fatalf("expected %d invalid answer messages but got %d:\n%s", expected, got, out.String())
+	}
+
+	if _, err := p.Input(q); err == nil {
+		t.Fatalf("expected an error when there is nothing left to read")
+	}
+}
+
+func TestScriptedPrompter(t *testing.T) {
+	p := NewScriptedPrompter(map[string]string{"Addr": ""})
+
+	if ans, err := p.Input(Question{Name: "Addr", Default: ":8080"}); err != nil || ans != ":8080" {
+		t.Fatalf("expected the default value on empty answer but got: %q (%v)", ans, err)
+	}
+
+	if _, err := p.Password(Question{Name: "Password"}); !errors.Is(err, ErrNoAnswer) {
+		t.Fatalf("expected %v but got: %v", ErrNoAnswer, err)
+	}
+}
+
+type testPortConfiguration struct {
+	Host string
+	Port int `min:"1" max:"65535"`
+}
+
+func TestAskInvalidAnswers(t *testing.T) {
+	// three invalid answers, then the first choice, "Use default".
+	in := strings.NewReader("localhost\nabc\n70000\n-1\n1\n")
+	out := new(bytes.Buffer)
+
+	// Init asks for all the fields, the current values are the defaults.
+	c := testPortConfiguration{Port: 8080}
+	if err := Init(filepath.Join(t.TempDir(), "config.yml"), &c, WithPrompter(NewStdinPrompter(in, out)), WithMaxAttempts(3)); err != nil {
+		t.Fatal(err)
+	}
+	configtest.AssertLoaded(t, c, testPortConfiguration{Host: "localhost", Port: 8080})
+
+	for _, expected := range []string{
+		"expected integer between 1 and 65535, got 'abc' (attempt 1 of 3)",
+		"expected integer between 1 and 65535, got '70000' (attempt 2 of 3)",
+		"Port: expected integer between 1 and 65535, got '-1'",
+		"1) Use default",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("expected output to contain: %q but got:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestAskInvalidAnswersSkip(t *testing.T) {
+	in := strings.NewReader("localhost\nabc\nSkip\n")
+
+	var c testPortConfiguration
+	configtest.Load(t, "", &c, WithFileDecoder(nil), WithPrompter(NewStdinPrompter(in, new(bytes.Buffer))), WithMaxAttempts(1), WithLogger(nil))
+	configtest.AssertLoaded(t, c, testPortConfiguration{Host: "localhost"})
+}
+
+func TestAskInvalidAnswersAbort(t *testing.T) {
+	var c testPortConfiguration
+	err := Load("", &c, WithFileDecoder(nil), WithPrompter(configtest.Answers(map[string]string{"Host": "localhost", "Port": "abc"})))
+
+	var promptErr *PromptError
+	if !errors.As(err, &promptErr) {
+		t.Fatalf("expected a prompt error but got: %v", err)
+	}
+
+	if expected, got := "invalid value of Port: expected integer between 1 and 65535, got 'abc'", err.Error(); expected != got {
+		t.Fatalf("expected error: %s but got: %s", expected, got)
+	}
+}
+
+func TestAskPrompterError(t *testing.T) {
+	// the input ends before the Port's answer, i.e an interrupted terminal.
+	var c testPortConfiguration
+	err := Load("", &c, WithFileDecoder(nil), WithPrompter(NewStdinPrompter(strings.NewReader("localhost\n"), new(bytes.Buffer))))
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("expected the prompter's error but got: %v", err)
+	}
+}
+
+type testNumbersConfiguration struct {
+	Int32   int32
+	Float64 float64
+	Uint8   uint8
+}
+
+func TestAskNumbers(t *testing.T) {
+	var c testNumbersConfiguration
+	configtest.Load(t, "", &c, WithFileDecoder(nil), WithPrompter(configtest.Answers(map[string]string{
+		"Int32":   "42",
+		"Float64": "1.5",
+		"Uint8":   "255",
+	})))
+	configtest.AssertLoaded(t, c, testNumbersConfiguration{Int32: 42, Float64: 1.5, Uint8: 255})
+}
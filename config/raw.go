@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// decodeRaw decodes the file's contents to a generic tree of the "format",
+// its objects are always map[string]interface{} values.
+func decodeRaw(data []byte, format string) (map[string]interface{}, error) {
+	fileDecoder, ok := decoders[normalizeFormat(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown configuration format: %q", format)
+	}
+
+	var raw map[string]interface{}
+	if err := fileDecoder(data, &raw); err != nil {
+		return nil, err
+	}
+
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+
+	return normalizeRaw(raw).(map[string]interface{}), nil
+}
+
+// normalizeRaw converts the map[interface{}]interface{} values, i.e of the yaml decoder,
+// to map[string]interface{} ones and the integral float64 values, i.e of the json decoder, to int ones, recursively.
+func normalizeRaw(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for key, elem := range value {
+			m[fmt.Sprintf("%v", key)] = normalizeRaw(elem)
+		}
+		return m
+	case map[string]interface{}:
+		for key, elem := range value {
+			value[key] = normalizeRaw(elem)
+		}
+		return value
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = normalizeRaw(elem)
+		}
+		return value
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < 1<<53 {
+			return int(value)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// lookupRaw returns the value of a field's name, i.e "DBCredentials.Host" or "Upstreams[0].Host", from a generic tree.
+// The keys are matched case-insensitively when there is no exact match,
+// as the decoders do, i.e the yaml's "addr" key of an untagged "Addr" field.
+func lookupRaw(raw map[string]interface{}, name string) (interface{}, bool) {
+	var cur interface{} = raw
+	for _, key := range strings.Split(name, ".") {
+		key, index, indexed := splitIndex(key)
+
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if cur, ok = lookupKey(m, key); !ok {
+			return nil, false
+		}
+
+		if indexed {
+			list, ok := cur.([]interface{})
+			if !ok || index >= len(list) {
+				return nil, false
+			}
+
+			cur = list[index]
+		}
+	}
+
+	return cur, true
+}
+
+// walkRawFields calls "fn" for each field of the struct value "v", see `walkFields`, which its key exists on the file's generic tree,
+// with its value on the tree and a function which replaces that value.
+// The keys are resolved through the fields' Go index by the "fileNaming", as the file's decoder does, see `fileNaming`,
+// while the fields' names are the ones of the "n", i.e the names of the flags, prompts and reports.
+func walkRawFields(raw map[string]interface{}, v reflect.Value, prefix string, n, fileNaming naming, fn rawFieldFunc) {
+	for _, f := range lookupFields(v.Type(), field{}, n) {
+		parent, key, ok := lookupRawIndex(raw, v.Type(), f.Index, fileNaming)
+		if !ok {
+			continue
+		}
+
+		f.Name = joinName(prefix, f.Name)
+		fValue := v.FieldByIndex(f.Index)
+		fn(f, fValue, parent[key], func(value interface{}) { parent[key] = value })
+
+		if f.Required && isSectionList(fValue.Type()) {
+			walkRawElements(parent[key], fValue, f.Name, n, fileNaming, fn)
+		}
+	}
+}
+
+type rawFieldFunc func(f field, fValue reflect.Value, value interface{}, set func(value interface{}))
+
+// walkRawElements calls the `walkRawFields` for each struct element of the slice or map "v" of sections, see `isSectionList`,
+// which exists on the "value", the generic list or object of the file.
+func walkRawElements(value interface{}, v reflect.Value, name string, n, fileNaming naming, fn rawFieldFunc) {
+	switch v.Kind() {
+	case reflect.Slice:
+		list, _ := value.([]interface{})
+		for i := 0; i < len(list) && i < v.Len(); i++ {
+			if m, ok := list[i].(map[string]interface{}); ok {
+				walkRawFields(m, v.Index(i), fmt.Sprintf("%s[%d]", name, i), n, fileNaming, fn)
+			}
+		}
+	case reflect.Map:
+		elems, _ := value.(map[string]interface{})
+		for _, key := range v.MapKeys() {
+			if m, ok := elems[key.String()].(map[string]interface{}); ok {
+				walkRawFields(m, v.MapIndex(key), joinName(name, key.String()), n, fileNaming, fn)
+			}
+		}
+	}
+}
+
+// lookupRawIndex returns the object of a generic tree which holds a field, by its Go index relative to the "typ", and the field's key.
+// Each struct field of the index is a key of the "fileNaming", except the ones that the decoder inlines, see `isInline`.
+// The squashed and prefixed sections keep their keys, they are flattened on the names only.
+func lookupRawIndex(raw map[string]interface{}, typ reflect.Type, index []int, fileNaming naming) (map[string]interface{}, string, bool) {
+	var (
+		parent map[string]interface{}
+		key    string
+		cur    interface{} = raw
+	)
+
+	for _, i := range index {
+		sf := typ.Field(i)
+		typ = sf.Type
+
+		if isSection(sf.Type) && isInline(sf, fileNaming) {
+			continue
+		}
+
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, "", false
+		}
+
+		if key, ok = findKey(m, fileNaming.fieldName(sf)); !ok {
+			return nil, "", false
+		}
+
+		parent, cur = m, m[key]
+	}
+
+	return parent, key, parent != nil
+}
+
+// splitIndex splits an indexed key, i.e "Upstreams[0]", to its name and index.
+func splitIndex(key string) (string, int, bool) {
+	if !strings.HasSuffix(key, "]") {
+		return key, 0, false
+	}
+
+	open := strings.LastIndexByte(key, '[')
+	if open == -1 {
+		return key, 0, false
+	}
+
+	index, err := strconv.Atoi(key[open+1 : len(key)-1])
+	if err != nil || index < 0 {
+		return key, 0, false
+	}
+
+	return key[:open], index, true
+}
+
+func lookupKey(m map[string]interface{}, key string) (interface{}, bool) {
+	key, ok := findKey(m, key)
+	return m[key], ok
+}
+
+// findKey returns the key of an object which matches the "key",
+// case-insensitively when there is no exact match, as the decoders do.
+func findKey(m map[string]interface{}, key string) (string, bool) {
+	if _, ok := m[key]; ok {
+		return key, true
+	}
+
+	for k := range m {
+		if strings.EqualFold(k, key) {
+			return k, true
+		}
+	}
+
+	return "", false
+}
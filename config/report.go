@@ -0,0 +1,111 @@
+package config
+
+import (
+	"reflect"
+)
+
+// Source is the kind of place that a configuration field's value came from.
+type Source string
+
+// The available sources of a `Report`.
+const (
+	// SourceDefault is reported for fields that were already filled before `Load`.
+	SourceDefault Source = "default"
+	// SourceFile is reported for fields that were set by the file decoder.
+	SourceFile Source = "file"
+	// SourceKV is reported for fields that were set by the key/value store.
+	SourceKV Source = "kv"
+	// SourceFlag is reported for fields that were set by the flag set.
+	SourceFlag Source = "flag"
+	// SourceEnv is reported for fields that were set by the environment variables.
+	SourceEnv Source = "env"
+	// SourceSecretStore is reported for fields that were set by the `SecretStore`.
+	SourceSecretStore Source = "secret-store"
+	// SourcePrompt is reported for fields that were answered through the `Prompter`.
+	SourcePrompt Source = "prompt"
+	// SourceHook is reported for fields that were changed by the `Hooks`.
+	SourceHook Source = "hook"
+)
+
+// Report holds information about a `Load` call,
+// pass a non-nil one through the `WithReport` option to fill it.
+type Report struct {
+	// Sources maps the fields' names, i.e "DBCredentials.Host", to the source
+	// that set their values last. Fields that were never set are missing.
+	Sources map[string]Source
+	// Warnings are the non-fatal issues of the loading, i.e deprecated fields.
+	Warnings []string
+}
+
+// WithReport makes `Load` to fill the "r" with information
+// about where each of the configuration fields came from.
+func WithReport(r *Report) Option {
+	return func(o *options) {
+		o.report = r
+	}
+}
+
+// Source returns the source of a field's value by its name
+// or an empty `Source` if the field was never set.
+func (r *Report) Source(name string) Source {
+	if r == nil {
+		return ""
+	}
+
+	return r.Sources[name]
+}
+
+func (r *Report) set(name string, source Source) {
+	if r.Sources == nil {
+		r.Sources = make(map[string]Source)
+	}
+
+	r.Sources[name] = source
+}
+
+func (r *Report) warn(warning string) {
+	if r != nil {
+		r.Warnings = append(r.Warnings, warning)
+	}
+}
+
+// record reports the "source" for any field of the "dest" whose value was changed by the "stage".
+// The "stage" is always executed, even if the report is nil.
+func (r *Report) record(dest interface{}, n naming, source Source, stage func()) {
+	if r == nil {
+		stage()
+		return
+	}
+
+	v := reflect.ValueOf(dest).Elem()
+
+	before := make(map[string]interface{})
+	walkFields(v, "", n, func(f field, fieldVal reflect.Value) {
+		if fieldVal.CanInterface() && !isSectionList(fieldVal.Type()) {
+			before[f.Name] = fieldVal.Interface()
+		}
+	})
+
+	stage()
+
+	walkFields(v, "", n, func(f field, fieldVal reflect.Value) {
+		if fieldVal.CanInterface() && !isSectionList(fieldVal.Type()) {
+			if !reflect.DeepEqual(before[f.Name], fieldVal.Interface()) {
+				r.set(f.Name, source)
+			}
+		}
+	})
+}
+
+// recordDefaults reports the `SourceDefault` for any non-zero field of the "dest".
+func (r *Report) recordDefaults(dest interface{}, n naming) {
+	if r == nil {
+		return
+	}
+
+	walkFields(reflect.ValueOf(dest).Elem(), "", n, func(f field, fieldVal reflect.Value) {
+		if fieldVal.CanInterface() && !isSectionList(fieldVal.Type()) && !isZero(fieldVal) {
+			r.set(f.Name, SourceDefault)
+		}
+	})
+}
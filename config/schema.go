@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema returns the JSON Schema of the "dest" configuration's file in the "format", i.e "yaml" or "json",
+// the properties' names are the ones that the format's decoder expects.
+// The fields' non-zero values are the properties' defaults, except the secret ones which are "writeOnly",
+// their "help" tag is the description and the deprecated fields are marked as "deprecated".
+// No property is required as the missing fields can be provided by the other sources, i.e flags.
+func Schema(dest interface{}, format string) ([]byte, error) {
+	v := reflect.Indirect(reflect.ValueOf(dest))
+	if v.Kind() != reflect.Struct {
+		return nil, ErrBad
+	}
+
+	schema := objectSchema(v, fileNaming(format))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = v.Type().Name()
+
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+func objectSchema(v reflect.Value, n naming) map[string]interface{} {
+	properties := make(map[string]interface{})
+
+	typ := v.Type()
+	for i, numField := 0, typ.NumField(); i < numField; i++ {
+		sf := typ.Field(i)
+		if sf.Type.Kind() == reflect.Ptr || sf.PkgPath != "" && !(sf.Anonymous && isSection(sf.Type)) || sf.Tag.Get(n.tag) == "-" {
+			continue
+		}
+
+		name := n.fieldName(sf)
+		fieldVal := v.Field(i)
+
+		if isSection(sf.Type) && isInline(sf, n) {
+			for key, prop := range objectSchema(fieldVal, n)["properties"].(map[string]interface{}) {
+				if _, ok := properties[key]; !ok { // a field of the parent shadows it.
+					properties[key] = prop
+				}
+			}
+			continue
+		}
+
+		if isSection(sf.Type) {
+			prop := objectSchema(fieldVal, n)
+			if help := sf.Tag.Get("help"); help != "" {
+				prop["description"] = help
+			}
+			properties[name] = prop
+			continue
+		}
+
+		f := newField(sf, []int{i}, name)
+		prop := typeSchema(sf.Type, f, n)
+
+		description := f.Help
+		if typeDescription, ok := prop["description"].(string); ok {
+			description = strings.TrimSpace(description + " " + typeDescription)
+		}
+
+		if f.Deprecated != "" {
+			prop["deprecated"] = true
+			description = strings.TrimSpace(fmt.Sprintf("%s Deprecated: %s.", description, f.Deprecated))
+		}
+
+		if description != "" {
+			prop["description"] = description
+		}
+
+		switch {
+		case f.Secret:
+			prop["writeOnly"] = true
+		case f.Sensitive, isSectionList(sf.Type):
+			// the default value is not revealed, the elements may have secret and sensitive fields too.
+		case !isZero(fieldVal) && fieldVal.CanInterface():
+			prop["default"] = schemaDefault(f, fieldVal)
+		}
+
+		properties[name] = prop
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+func typeSchema(typ reflect.Type, f field, n naming) map[string]interface{} {
+	switch {
+	case typ == timeType:
+		if timeLayout(f) == time.RFC3339 {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		return map[string]interface{}{"type": "string", "description": "A date/time of layout " + timeLayout(f) + "."}
+	case typ == durationType:
+		// i.e "30s" for yaml or nanoseconds for json.
+		return map[string]interface{}{"type": []string{"string", "integer"}}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Struct:
+		if isSection(typ) { // an element of a slice or a map.
+			return objectSchema(reflect.New(typ).Elem(), n)
+		}
+		return map[string]interface{}{}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(typ.Elem(), f, n)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(typ.Elem(), f, n)}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func schemaDefault(f field, v reflect.Value) interface{} {
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return formatTime(value, f)
+	case time.Duration:
+		return value.String()
+	default:
+		return value
+	}
+}
@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/kataras/pkg/config"
+)
+
+func TestSchema(t *testing.T) {
+	type configuration struct {
+		Addr    string        `json:"addr" help:"the address to listen on"`
+		Timeout time.Duration `json:"timeout"`
+		Peers   []string      `json:"peers"`
+		Secret  string        `json:"secret" config:"secret"`
+		Legacy  int           `json:"legacy" deprecated:"use timeout"`
+		DB      struct {
+			Port int `json:"port"`
+		} `json:"db"`
+	}
+
+	c := configuration{Addr: ":8080", Secret: "123"}
+	b, err := Schema(c, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"addr":    map[string]interface{}{"type": "string", "description": "the address to listen on", "default": ":8080"},
+		"timeout": map[string]interface{}{"type": []interface{}{"string", "integer"}},
+		"peers":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		"secret":  map[string]interface{}{"type": "string", "writeOnly": true},
+		"legacy":  map[string]interface{}{"type": "integer", "deprecated": true, "description": "Deprecated: use timeout."},
+		"db": map[string]interface{}{"type": "object", "properties": map[string]interface{}{
+			"port": map[string]interface{}{"type": "integer"},
+		}},
+	}
+
+	if properties := got["properties"]; !reflect.DeepEqual(expected, properties) {
+		t.Fatalf("expected properties:\n%#+v\nbut got:\n%#+v", expected, properties)
+	}
+}
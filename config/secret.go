@@ -0,0 +1,340 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SecretStore is the interface which should be implemented by the local secret stores, i.e a keyring,
+// in order to keep the values of the secret fields, tagged as 'config:"secret"' or 'config:"password"',
+// between runs instead of asking for them each time.
+//
+// See `WithSecretStore`, `NewFileSecretStore` and `NewSecretServiceStore`.
+type SecretStore interface {
+	// Get returns the secret of a key, the field's name, and reports whether it exists.
+	Get(key string) (value string, ok bool, err error)
+	// Set stores the secret of a key.
+	Set(key, value string) error
+}
+
+// WithSecretStore makes `Load` to read the missing secret fields from the "store",
+// right before the survey, and to store the secret fields' answers after a successful survey.
+// The store's keys are the fields' names, i.e "DBCredentials.Password".
+func WithSecretStore(store SecretStore) Option {
+	return func(o *options) {
+		o.secrets = store
+	}
+}
+
+// loadSecrets sets the missing secret fields from the store
+// and returns the ones that are still missing.
+// Returns a join of the `*ConversionError`s, it stops on the first error of the store itself.
+func loadSecrets(store SecretStore, dest interface{}, n naming, provided presence) (missing []field, err error) {
+	var (
+		errs     []error
+		storeErr error
+	)
+
+	visitMissingFields(dest, n, provided, func(f field, fValue reflect.Value) {
+		if !f.Secret || storeErr != nil {
+			return
+		}
+
+		got, ok, getErr := store.Get(f.Name)
+		if getErr != nil {
+			storeErr = fmt.Errorf("secret store: %s: %w", f.Name, getErr)
+			return
+		}
+
+		if !ok {
+			missing = append(missing, f)
+			return
+		}
+
+		if convErr := assignString(fValue, f, got); convErr != nil {
+			errs = append(errs, &ConversionError{Field: f.Name, Source: SourceSecretStore, Key: f.Name, Err: convErr})
+			return
+		}
+
+		provided.add(f.Name)
+	})
+
+	return missing, errors.Join(append(errs, storeErr)...)
+}
+
+// storeSecrets stores the values of the "fields" which are set.
+func storeSecrets(store SecretStore, dest interface{}, n naming, fields []field) (err error) {
+	names := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		names[f.Name] = struct{}{}
+	}
+
+	walkFields(reflect.ValueOf(dest).Elem(), "", n, func(f field, fValue reflect.Value) {
+		if _, ok := names[f.Name]; !ok || err != nil || isZero(fValue) {
+			return
+		}
+
+		if setErr := store.Set(f.Name, formatValue(f, fValue)); setErr != nil {
+			err = fmt.Errorf("secret store: %s: %w", f.Name, setErr)
+		}
+	})
+
+	return
+}
+
+// FileSecretStore is a `SecretStore` which keeps the secrets in a local file,
+// encrypted with AES-256-GCM by a key which is kept in a second file.
+// It's safe for concurrent use.
+type FileSecretStore struct {
+	// Path is the encrypted file's path.
+	Path string
+	// KeyPath is the path of the file which holds the 32 bytes key.
+	// It's created, with a random key, on the first `Set` if it does not exist.
+	KeyPath string
+
+	mu sync.Mutex
+}
+
+var _ SecretStore = (*FileSecretStore)(nil)
+
+// NewFileSecretStore returns a new file-based, encrypted, `SecretStore`,
+// i.e NewFileSecretStore("$HOME/.myapp/secrets", "$HOME/.myapp/secrets.key").
+// Environment variables in the paths are expanded.
+func NewFileSecretStore(path, keyPath string) *FileSecretStore {
+	return &FileSecretStore{Path: os.ExpandEnv(path), KeyPath: os.ExpandEnv(keyPath)}
+}
+
+func (s *FileSecretStore) key(create bool) ([]byte, error) {
+	key, err := ioutil.ReadFile(s.KeyPath)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("%s: the key should be 32 bytes long", s.KeyPath)
+		}
+		return key, nil
+	}
+
+	if !create || !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	key = make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	if err = writeFileAtomic(s.KeyPath, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// read returns the decrypted secrets, a missing file is an empty store.
+func (s *FileSecretStore) read() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	data, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return secrets, nil
+		}
+		return nil, err
+	}
+
+	key, err := s.key(false)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%s: corrupted file", s.Path)
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+
+	if err = json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+
+	return secrets, nil
+}
+
+func (s *FileSecretStore) write(secrets map[string]string) error {
+	key, err := s.key(true)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.Path, gcm.Seal(nonce, nonce, plaintext, nil))
+}
+
+// writeFileAtomic writes a private, 0600, file through a temporary one, creating its directory if needed.
+func writeFileAtomic(filename string, data []byte) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Get implements the `SecretStore` interface.
+func (s *FileSecretStore) Get(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.read()
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := secrets[key]
+	return value, ok, nil
+}
+
+// Set implements the `SecretStore` interface.
+func (s *FileSecretStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	secrets[key] = value
+	return s.write(secrets)
+}
+
+// SecretServiceStore is a `SecretStore` of the freedesktop.org Secret Service,
+// i.e GNOME Keyring and KWallet, through the libsecret's "secret-tool" command.
+type SecretServiceStore struct {
+	// Service is the "service" attribute of the secrets, i.e the application's name.
+	Service string
+	// Command is the path of the "secret-tool" command.
+	// Defaults to "secret-tool", it should be in the PATH.
+	Command string
+}
+
+var _ SecretStore = (*SecretServiceStore)(nil)
+
+// NewSecretServiceStore returns a new `SecretStore` of the Secret Service
+// for the "service", i.e the application's name. The "secret-tool" command is required.
+func NewSecretServiceStore(service string) *SecretServiceStore {
+	return &SecretServiceStore{Service: service, Command: "secret-tool"}
+}
+
+// run executes the command and returns its output and its error's output.
+func (s *SecretServiceStore) run(stdin io.Reader, args ...string) (string, string, error) {
+	command := s.Command
+	if command == "" {
+		command = "secret-tool"
+	}
+
+	cmd := exec.Command(command, args...)
+	cmd.Stdin = stdin
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	err := cmd.Run()
+	return stdout.String(), strings.TrimSpace(stderr.String()), err
+}
+
+func commandError(command string, err error, stderr string) error {
+	if stderr == "" {
+		return fmt.Errorf("%s: %w", command, err)
+	}
+
+	return fmt.Errorf("%s: %w: %s", command, err, stderr)
+}
+
+// Get implements the `SecretStore` interface.
+func (s *SecretServiceStore) Get(key string) (string, bool, error) {
+	out, stderr, err := s.run(nil, "lookup", "service", s.Service, "key", key)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr == "" {
+			return "", false, nil // not found.
+		}
+		return "", false, commandError("secret-tool lookup", err, stderr)
+	}
+
+	return strings.TrimSuffix(out, "\n"), true, nil
+}
+
+// Set implements the `SecretStore` interface.
+func (s *SecretServiceStore) Set(key, value string) error {
+	label := s.Service + ": " + key
+	if _, stderr, err := s.run(strings.NewReader(value), "store", "--label="+label, "service", s.Service, "key", key); err != nil {
+		return commandError("secret-tool store", err, stderr)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package config_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+func TestFileSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileSecretStore(filepath.Join(dir, "secrets"), filepath.Join(dir, "secrets.key"))
+
+	if _, ok, err := store.Get("DB.Password"); err != nil || ok {
+		t.Fatalf("expected an empty store but got: %v, %v", ok, err)
+	}
+
+	if err := store.Set("DB.Password", "123456"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(data, []byte("123456")) {
+		t.Fatalf("expected the secrets file to be encrypted")
+	}
+
+	// a new instance reads the same file.
+	value, ok, err := NewFileSecretStore(store.Path, store.KeyPath).Get("DB.Password")
+	if err != nil || !ok {
+		t.Fatalf("expected the secret to exist but got: %v, %v", ok, err)
+	}
+
+	if expected, got := "123456", value; expected != got {
+		t.Fatalf("expected secret %s but got: %s", expected, got)
+	}
+}
+
+func TestLoadSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileSecretStore(filepath.Join(dir, "secrets"), filepath.Join(dir, "secrets.key"))
+	fsys := configtest.FS(map[string]string{"config.yml": "Addr: :8080\nServerName: app\nDebug: true\nYear: 2019\nDB:\n  Username: kataras\n  Host: localhost"})
+
+	// first run, the password is asked and stored.
+	answers := configtest.Answers(map[string]string{"DB.Password": "123456"})
+	var c testConfiguration
+	configtest.Load(t, "config.yml", &c, WithFS(fsys), WithPrompter(answers), WithSecretStore(store))
+
+	if expected, got := []string{"DB.Password"}, answers.Asked(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected to be asked for %v but got: %v", expected, got)
+	}
+
+	// second run, the password is read from the store.
+	answers = configtest.Answers(nil)
+	c = testConfiguration{}
+	report := configtest.Load(t, "config.yml", &c, WithFS(fsys), WithPrompter(answers), WithSecretStore(store))
+
+	if got := answers.Asked(); len(got) > 0 {
+		t.Fatalf("expected nothing to be asked but got: %v", got)
+	}
+
+	if expected, got := "123456", c.DB.Password; expected != got {
+		t.Fatalf("expected password %s but got: %s", expected, got)
+	}
+
+	configtest.AssertSource(t, report, "DB.Password", SourceSecretStore)
+}
+
+func TestLoadSecretStoreConversionErrors(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileSecretStore(filepath.Join(dir, "secrets"), filepath.Join(dir, "secrets.key"))
+	for key, value := range map[string]string{"Port": "abc", "AdminPort": "xyz"} {
+		if err := store.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var c struct {
+		Port      int `config:"secret"`
+		AdminPort int `config:"secret"`
+	}
+	err := Load("", &c, WithFileDecoder(nil), WithSecretStore(store), WithoutSurvey)
+
+	for _, expected := range []string{"Port: expected integer", "AdminPort: expected integer"} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected the joined error to contain: %s but got: %v", expected, err)
+		}
+	}
+}
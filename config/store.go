@@ -0,0 +1,272 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Store holds a configuration which can be read by many goroutines
+// while it's reloaded or updated by others, i.e the handlers of a server.
+//
+// The values are never modified in place, each change stores a new copy of the configuration,
+// so a value returned by `Get` is a consistent snapshot which should be treated as read-only.
+// The copies are deep, a change of a slice, map or pointer field never reaches an earlier snapshot.
+type Store[T any] struct {
+	value    atomic.Pointer[T]
+	defaults T
+
+	mu          sync.Mutex // serializes the writers.
+	subscribers map[chan *T]struct{}
+}
+
+// NewStore returns a new `Store` which holds a copy of the "defaults",
+// they are the starting point of each `Load` and `Reload`.
+func NewStore[T any](defaults T) *Store[T] {
+	s := &Store[T]{
+		defaults:    *deepCopy(&defaults),
+		subscribers: make(map[chan *T]struct{}),
+	}
+	s.value.Store(deepCopy(&s.defaults))
+	return s
+}
+
+// Get returns the current configuration without locking.
+// The result should not be modified, use `Update` instead.
+func (s *Store[T]) Get() *T {
+	return s.value.Load()
+}
+
+// Set replaces the configuration with the "value" and notifies the subscribers.
+// The "value" should not be modified after this call.
+func (s *Store[T]) Set(value *T) {
+	s.mu.Lock()
+	s.set(value)
+	s.mu.Unlock()
+}
+
+func (s *Store[T]) set(value *T) {
+	s.value.Store(value)
+
+	for ch := range s.subscribers {
+		// keep only the latest value for slow subscribers.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- value
+	}
+}
+
+// Update calls the "fn" with a copy of the current configuration,
+// stores the modified copy and notifies the subscribers.
+func (s *Store[T]) Update(fn func(c *T)) {
+	s.mu.Lock()
+	c := deepCopy(s.value.Load())
+	fn(c)
+	s.set(c)
+	s.mu.Unlock()
+}
+
+// Subscribe returns a channel which receives the new configuration on each change
+// and a function which stops the subscription and closes the channel.
+// A subscriber which is not ready to receive misses the intermediate changes, it always receives the latest one.
+func (s *Store[T]) Subscribe() (<-chan *T, func()) {
+	ch := make(chan *T, 1)
+
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subscribers, ch)
+			close(ch)
+			s.mu.Unlock()
+		})
+	}
+}
+
+// Load calls the `Load` function with a copy of the defaults and stores the result on success.
+// On failure the current configuration is kept.
+func (s *Store[T]) Load(fullpath string, optional ...Option) error {
+	c := deepCopy(&s.defaults)
+	if err := Load(fullpath, c, optional...); err != nil {
+		return err
+	}
+
+	s.Set(c)
+	return nil
+}
+
+// Reload same as `Load` but without asking anything,
+// the differences of the new configuration are logged, see `Diff` and `WithLogger`.
+func (s *Store[T]) Reload(fullpath string, optional ...Option) error {
+	optional = append(optional[:len(optional):len(optional)], WithoutSurvey)
+	opts := newOptions(optional)
+
+	prev := s.Get()
+	if err := s.Load(fullpath, optional...); err != nil {
+		opts.logf("reload %s: %v", fullpath, err)
+		return err
+	}
+
+	if changes := Diff(prev, s.Get()); len(changes) > 0 {
+		lines := make([]string, 0, len(changes))
+		for _, change := range changes {
+			lines = append(lines, change.String())
+		}
+		opts.logf("reload %s: %s", fullpath, strings.Join(lines, ", "))
+	}
+
+	return nil
+}
+
+// Watch reloads the configuration, see `Reload`, each time that the file's contents
+// are changed, they are checked every "interval", and, if `WithKV` is used, each time that the key/value store is changed.
+// It blocks until the context is canceled.
+// A failed reload keeps the current configuration, its error is logged, see `WithLogger`,
+// and passed to the function of the `WithReloadErrorHandler` option.
+func (s *Store[T]) Watch(ctx context.Context, fullpath string, interval time.Duration, optional ...Option) error {
+	opts := newOptions(optional)
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	var kvEvents <-chan KVEvent
+	if opts.kv != nil {
+		ch, err := opts.kv.Watch(ctx, kvPrefix(opts.kvPrefix))
+		if err != nil {
+			return err
+		}
+		kvEvents = ch
+	}
+
+	prev, _, _ := readFile(fullpath, opts)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-kvEvents:
+			if !ok {
+				kvEvents = nil // the store's watch was stopped, keep watching the file.
+				continue
+			}
+		case <-ticker.C:
+			data, _, err := readFile(fullpath, opts)
+			if err != nil || bytes.Equal(data, prev) {
+				continue // i.e the file is being written, try on the next tick.
+			}
+			prev = data
+		}
+
+		if err := s.Reload(fullpath, optional...); err != nil && opts.reloadErrorHandler != nil {
+			opts.reloadErrorHandler(err)
+		}
+	}
+}
+
+// WithReloadErrorHandler registers a function which is called with the error of each failed reload
+// of the `Store.Watch`, i.e to report an invalid configuration file, the current configuration is kept.
+// Defaults to nil; the errors are only logged, see `WithLogger`.
+func WithReloadErrorHandler(fn func(err error)) Option {
+	return func(o *options) {
+		o.reloadErrorHandler = fn
+	}
+}
+
+// deepCopy returns a copy of the "value" which shares no slices, maps or pointers with it.
+func deepCopy[T any](value *T) *T {
+	c := new(T)
+	reflect.ValueOf(c).Elem().Set(copyValue(reflect.ValueOf(value).Elem(), make(map[copyKey]reflect.Value)))
+	return c
+}
+
+// copyKey identifies a pointer, map or slice which is already copied.
+type copyKey struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// copyValue returns a deep copy of "v", the unexported fields of structs,
+// the interfaces, functions and channels are copied as they are.
+// The pointers, maps and slices which are met again, i.e a pointer to a parent struct,
+// are replaced by their first copy, so cycles are kept instead of followed forever.
+func copyValue(v reflect.Value, copies map[copyKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+
+		c := reflect.New(v.Type().Elem())
+		copies[key] = c
+		c.Elem().Set(copyValue(v.Elem(), copies))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < c.NumField(); i++ {
+			if fieldVal := c.Field(i); fieldVal.CanSet() {
+				fieldVal.Set(copyValue(v.Field(i), copies))
+			}
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), copies))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		key := copyKey{ptr: v.Pointer(), len: v.Len(), typ: v.Type()}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		copies[key] = c
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), copies))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		copies[key] = c
+		for iter := v.MapRange(); iter.Next(); {
+			c.SetMapIndex(iter.Key(), copyValue(iter.Value(), copies))
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,191 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/kataras/pkg/config"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore(testConfiguration{Addr: ":8080"})
+
+	updates, stop := s.Subscribe()
+	defer stop()
+
+	prev := s.Get()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.Update(func(c *testConfiguration) { c.Year++ })
+		}()
+		go func() {
+			defer wg.Done()
+			_ = s.Get().Addr
+		}()
+	}
+	wg.Wait()
+
+	if expected, got := 10, s.Get().Year; expected != got {
+		t.Fatalf("expected year to be %d but got: %d", expected, got)
+	}
+
+	if expected, got := 0, prev.Year; expected != got {
+		t.Fatalf("expected the previous snapshot to be kept as %d but got: %d", expected, got)
+	}
+
+	select {
+	case got := <-updates:
+		if got != s.Get() {
+			t.Fatalf("expected the subscriber to receive the latest configuration but got: %#+v", got)
+		}
+	default:
+		t.Fatalf("expected a change notification")
+	}
+}
+
+func TestStoreWatch(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(filename, []byte("Addr: :8080"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStore(testConfiguration{ServerName: "my server"})
+	if err := s.Load(filename, WithoutSurvey); err != nil {
+		t.Fatal(err)
+	}
+
+	updates, stop := s.Subscribe()
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Watch(ctx, filename, 10*time.Millisecond, WithLogger(nil))
+
+	time.Sleep(20 * time.Millisecond)
+	if err := ioutil.WriteFile(filename, []byte("Addr: :9090"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-updates:
+		if expected := (testConfiguration{Addr: ":9090", ServerName: "my server"}); expected != *got {
+			t.Fatalf("expected reloaded configuration %#+v but got: %#+v", expected, *got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout while waiting for the reload")
+	}
+}
+
+func TestStoreCopies(t *testing.T) {
+	type storeConfiguration struct {
+		Hosts  []string          `yaml:"Hosts"`
+		Labels map[string]string `yaml:"Labels"`
+	}
+
+	s := NewStore(storeConfiguration{Hosts: []string{"a"}, Labels: map[string]string{"env": "dev"}})
+	prev := s.Get()
+
+	s.Update(func(c *storeConfiguration) {
+		c.Hosts[0] = "b"
+		c.Labels["env"] = "prod"
+	})
+
+	if expected, got := "a", prev.Hosts[0]; expected != got {
+		t.Fatalf("expected the previous snapshot's host to be kept as %q but got: %q", expected, got)
+	}
+
+	if expected, got := "dev", prev.Labels["env"]; expected != got {
+		t.Fatalf("expected the previous snapshot's label to be kept as %q but got: %q", expected, got)
+	}
+
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(filename, []byte("Labels:\n  region: eu"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Load(filename, WithoutSurvey); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "eu", s.Get().Labels["region"]; expected != got {
+		t.Fatalf("expected the loaded label to be %q but got: %q", expected, got)
+	}
+
+	if expected, got := 1, len(prev.Labels); expected != got {
+		t.Fatalf("expected the previous snapshot to be kept with %d label but got: %d", expected, got)
+	}
+}
+
+func TestStoreWatchReloadError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(filename, []byte("Addr: :8080"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStore(testConfiguration{})
+	if err := s.Load(filename, WithoutSurvey); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Watch(ctx, filename, 10*time.Millisecond, WithReloadErrorHandler(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}))
+
+	time.Sleep(20 * time.Millisecond)
+	if err := ioutil.WriteFile(filename, []byte("Addr: [:9090"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errs:
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) {
+			t.Fatalf("expected a file error but got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout while waiting for the reload error")
+	}
+
+	if expected, got := ":8080", s.Get().Addr; expected != got {
+		t.Fatalf("expected the current configuration to be kept with %q but got: %q", expected, got)
+	}
+}
+
+func TestStoreCopiesCycles(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	type cycleConfiguration struct {
+		Head *node
+	}
+
+	head := &node{Name: "a"}
+	head.Next = &node{Name: "b", Next: head}
+
+	s := NewStore(cycleConfiguration{Head: head})
+	got := s.Get().Head
+	if got == head || got.Next == head.Next {
+		t.Fatalf("expected the nodes to be copied")
+	}
+
+	if got.Next.Next != got {
+		t.Fatalf("expected the copied nodes to keep their cycle")
+	}
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 )
@@ -13,41 +15,269 @@ import (
 //
 // Remember, "dest" should be a pointer to a struct's instance.
 //
+// The questions are asked through the `SurveyPrompter`,
+// use the `WithPrompter` option of `Load` to change that.
+//
 // If not any field to be prompted for value then this function does nothing.
 // Returns true if it was something to ask, otherwise false.
+// It stops on the first aborted question, see `WithMaxAttempts`,
+// use `Ask` instead to get its error.
 func TryAsk(dest interface{}) bool {
+	asked, _ := Ask(dest)
+	return asked
+}
+
+// Ask same as `TryAsk` but it returns the error of an aborted question too, a `*PromptError`,
+// or of the prompter itself. Returns `ErrBad` if the "dest" is not a pointer to a struct.
+func Ask(dest interface{}) (asked bool, err error) {
 	if !ok(dest) {
-		return false
+		return false, ErrBad
 	}
 
-	visitMissingFields(dest, func(f field, fValue reflect.Value) {
-		var unusedAns interface{}
-		fieldTyp := fValue.Type()
-		survey.AskOne(makePrompt(fieldTyp, f), &unusedAns, makeValidator(fieldTyp, fValue))
+	return ask(dest, newOptions(nil))
+}
+
+func ask(dest interface{}, opts options) (asked bool, err error) {
+	visitMissingFields(dest, opts.naming, opts.provided, func(f field, fValue reflect.Value) {
+		if err != nil || isSectionList(fValue.Type()) {
+			return
+		}
+
+		asked = true
+		err = askField(f, fValue, "", opts)
 	})
 
-	return true
+	return
 }
 
-func makePrompt(fieldTyp reflect.Type, f field) survey.Prompt {
-	fieldName := f.Name
+// askAll asks for all the fields, not only the missing ones, except the deprecated ones.
+func askAll(dest interface{}, opts options) error {
+	var err error
+	walkFields(reflect.ValueOf(dest).Elem(), "", opts.naming, func(f field, fValue reflect.Value) {
+		if err == nil && f.Required && f.Deprecated == "" && !isSectionList(fValue.Type()) {
+			err = askField(f, fValue, "", opts)
+		}
+	})
+
+	return err
+}
+
+// DefaultMaxAttempts is the default number of invalid answers
+// that are accepted for a single question, see `WithMaxAttempts`.
+const DefaultMaxAttempts = 3
+
+// WithMaxAttempts sets the number of invalid answers that are accepted for a single question,
+// after that the user chooses to use the question's default value, to skip the field or to abort,
+// an aborted question fails the `Load` with a `*PromptError`.
+//
+// Defaults to `DefaultMaxAttempts`.
+func WithMaxAttempts(n int) Option {
+	return func(o *options) {
+		o.maxAttempts = n
+	}
+}
+
+// PromptError is returned by `Load` when the user aborts a question
+// after too many invalid answers, see `WithMaxAttempts`.
+type PromptError struct {
+	// Field is the field's full name, i.e "Server.Port".
+	Field string
+	// Answer is the last invalid answer, redacted if the field is secret or its fingerprint if it's sensitive.
+	Answer string
+	// Err describes why the answer is invalid, i.e "expected integer between 1 and 65535, got 'abc'".
+	Err error
+}
+
+func (e *PromptError) Error() string {
+	return fmt.Sprintf("invalid value of %s: %v", e.Field, e.Err)
+}
+
+func (e *PromptError) Unwrap() error {
+	return e.Err
+}
+
+// The choices that are offered when the attempts of a question are over.
+const (
+	choiceUseDefault = "Use default"
+	choiceSkip       = "Skip"
+	choiceAbort      = "Abort"
+)
+
+// askField asks for a field's value, the "reason" is shown next to the question's message, if any.
+// Invalid answers are asked again, up to the max attempts, see `WithMaxAttempts`.
+func askField(f field, fValue reflect.Value, reason string, opts options) error {
+	p := opts.prompter
+	fieldTyp := fValue.Type()
+	q := makeQuestion(fieldTyp, f)
+	if f.Help != "" {
+		q.Help = f.Help
+	}
+
+	if reason != "" {
+		q.Message += " (" + reason + ")"
+	}
+
+	// the current value is the default one, i.e on `Init`; secrets and sensitive values are never shown.
+	if !f.Secret && !f.Sensitive && !isZero(fValue) {
+		q.Default = formatValue(f, fValue)
+	}
 
 	// if it's a boolean then show a confirmation prompt.
 	if fieldTyp.Kind() == reflect.Bool {
-		return &survey.Confirm{
+		ans, err := p.Confirm(q)
+		if err != nil {
+			return promptFailure(f, err)
+		}
+
+		fValue.SetBool(ans)
+		opts.provided.add(f.Name)
+		return nil
+	}
+
+	maxAttempts := opts.maxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	var (
+		attempts   int
+		validated  bool
+		lastAnswer string
+		lastErr    error
+	)
+
+	validate := makeValidator(f, fieldTyp, fValue)
+	q.Validate = func(answer string) error {
+		validated = true
+		if lastErr = validate(answer); lastErr == nil {
+			return nil
+		}
+
+		attempts++
+		lastAnswer = answer
+		if attempts >= maxAttempts {
+			return nil // stop the prompter's own retries, the choices are offered next.
+		}
+
+		return fmt.Errorf("%w (attempt %d of %d)", lastErr, attempts, maxAttempts)
+	}
+
+	for attempts < maxAttempts {
+		validated, lastErr = false, nil
+
+		// if it's a secret then show a password (replaces text to ****) prompt.
+		var err error
+		if f.Secret {
+			_, err = p.Password(q)
+		} else {
+			_, err = p.Input(q)
+		}
+
+		if !validated {
+			// the prompter failed on its own, i.e interrupted or no terminal.
+			return promptFailure(f, err)
+		}
+
+		if lastErr == nil {
+			opts.provided.add(f.Name)
+			return nil
+		}
+		// the prompter returned the validation error instead of asking again.
+	}
+
+	return askFallback(f, fValue, q, &PromptError{Field: f.Name, Answer: maskValue(f, lastAnswer), Err: lastErr}, opts)
+}
+
+// promptFailure returns the error of a prompter which failed on its own, if any.
+// A question without a scripted answer is not a failure, the field is kept as it's, see `ErrNoAnswer`.
+func promptFailure(f field, err error) error {
+	if err == nil || errors.Is(err, ErrNoAnswer) {
+		return nil
+	}
+
+	return fmt.Errorf("prompt: %s: %w", f.Name, err)
+}
+
+// askFallback asks what to do with a field that its attempts are over.
+func askFallback(f field, fValue reflect.Value, q Question, promptErr *PromptError, opts options) error {
+	// the question's default is not shown for secrets.
+	def, defErr := convertString(q.Default, f, fValue.Type())
+
+	choices := []string{choiceSkip, choiceAbort}
+	if q.Default != "" && defErr == nil {
+		choices = append([]string{choiceUseDefault}, choices...)
+	}
+
+	choice, err := opts.prompter.Select(Question{
+		Name:    f.Name,
+		Message: fmt.Sprintf("%s: %v", f.Name, promptErr.Err),
+		Help:    "Too many invalid answers.",
+		Default: choices[0],
+		Options: choices,
+	})
+	if err != nil {
+		return promptErr
+	}
+
+	switch choice {
+	case choiceUseDefault:
+		fValue.Set(reflect.ValueOf(def))
+		opts.provided.add(f.Name)
+	case choiceSkip:
+		opts.warnf("%s skipped: %v", f.Name, promptErr.Err)
+	default:
+		return promptErr
+	}
+
+	return nil
+}
+
+func makeQuestion(fieldTyp reflect.Type, f field) Question {
+	fieldName := f.Name
+
+	if fieldTyp.Kind() == reflect.Bool {
+		return Question{
+			Name:    fieldName,
 			Help:    fmt.Sprintf("The provided type of '%s' should be %s.", fieldName, fieldTyp.Name()),
 			Message: fmt.Sprintf("%s?", fieldName),
+			Default: "false",
 		}
 	}
 
-	// if it's a secret then show a password (replaces text to ****) prompt.
 	if f.Secret {
-		return &survey.Password{
+		return Question{
+			Name:    fieldName,
 			Help:    fmt.Sprintf("The provided type of '%s' should be a secret of %s.", fieldName, fieldTyp.Name()),
 			Message: fmt.Sprintf("Please type the value for the setting '%s'", fieldName),
 		}
 	}
 
+	// if it's a date/time then show the expected layout and the current time as example.
+	if fieldTyp.AssignableTo(timeType) {
+		layout := timeLayout(f)
+		example := formatTime(time.Now(), f)
+		zone := "UTC"
+		if f.TZ != "" {
+			zone = f.TZ
+		}
+
+		return Question{
+			Name:    fieldName,
+			Default: example,
+			Help:    fmt.Sprintf("The provided value of '%s' should be a date/time (%s) of layout '%s', i.e %s, an RFC3339 one or a Unix timestamp.", fieldName, zone, layout, example),
+			Message: fmt.Sprintf("Please type the date/time for the setting '%s'", fieldName),
+		}
+	}
+
+	if fieldTyp == durationType {
+		return Question{
+			Name:    fieldName,
+			Default: "0s",
+			Help:    fmt.Sprintf("The provided value of '%s' should be a duration, i.e 30s or 1h30m.", fieldName),
+			Message: fmt.Sprintf("Please type the duration for the setting '%s'", fieldName),
+		}
+	}
+
 	// otherwise show an input with a default value as well in parenthesis ().
 	zero := reflect.Zero(fieldTyp)
 	var def string
@@ -55,35 +285,94 @@ func makePrompt(fieldTyp reflect.Type, f field) survey.Prompt {
 		def = fmt.Sprintf("%v", zero.Interface())
 	}
 
-	return &survey.Input{
+	return Question{
+		Name:    fieldName,
 		Default: def,
 		Help:    fmt.Sprintf("The provided type of '%s' should be %s.", fieldName, fieldTyp.Name()),
 		Message: fmt.Sprintf("Please type the value for the setting '%s'", fieldName),
 	}
 }
 
-func makeValidator(fieldTyp reflect.Type, fieldVal reflect.Value) survey.AskOpt {
-	validator := func(gotValue interface{}) error {
-		// gotValue can be bool(if confirmation) or string otherwise.
-		if _, ok := gotValue.(bool); ok {
-			// if it was bool, then we're ready to set it as it's.
-			fieldVal.Set(reflect.ValueOf(gotValue))
-			return nil
-		}
-
-		// value to set.
-		value := parseValue(gotValue, fieldTyp)
-		if value == nil {
-			gotTyp := reflect.TypeOf(value)
-			return fmt.Errorf("invalid type of value passed, expected: %s but got %s", fieldTyp.Name(), gotTyp.Name())
+func makeValidator(f field, fieldTyp reflect.Type, fieldVal reflect.Value) func(string) error {
+	return func(gotValue string) error {
+		value, err := convertString(gotValue, f, fieldTyp)
+		if err != nil {
+			return err
 		}
 
 		fieldVal.Set(reflect.ValueOf(value))
 		return nil
 	}
+}
+
+// SurveyPrompter is the default `Prompter`,
+// it asks through the terminal using the survey package.
+type SurveyPrompter struct{}
+
+var _ Prompter = SurveyPrompter{}
 
+func surveyValidator(q Question) survey.AskOpt {
 	return func(options *survey.AskOptions) error {
-		options.Validators = append(options.Validators, validator)
+		if q.Validate == nil {
+			return nil
+		}
+
+		options.Validators = append(options.Validators, func(ans interface{}) error {
+			switch v := ans.(type) {
+			case string:
+				return q.Validate(v)
+			case survey.OptionAnswer:
+				return q.Validate(v.Value)
+			default:
+				return q.Validate(fmt.Sprintf("%v", v))
+			}
+		})
 		return nil
 	}
 }
+
+// Input implements the `Prompter` interface.
+func (SurveyPrompter) Input(q Question) (ans string, err error) {
+	err = survey.AskOne(&survey.Input{
+		Default: q.Default,
+		Help:    q.Help,
+		Message: q.Message,
+	}, &ans, surveyValidator(q))
+	return
+}
+
+// Password implements the `Prompter` interface.
+func (SurveyPrompter) Password(q Question) (ans string, err error) {
+	err = survey.AskOne(&survey.Password{
+		Help:    q.Help,
+		Message: q.Message,
+	}, &ans, surveyValidator(q))
+	return
+}
+
+// Confirm implements the `Prompter` interface.
+func (SurveyPrompter) Confirm(q Question) (ans bool, err error) {
+	def, _ := parseYesNo(q.Default)
+	err = survey.AskOne(&survey.Confirm{
+		Default: def,
+		Help:    q.Help,
+		Message: q.Message,
+	}, &ans)
+	return
+}
+
+// Select implements the `Prompter` interface.
+func (SurveyPrompter) Select(q Question) (ans string, err error) {
+	prompt := &survey.Select{
+		Help:    q.Help,
+		Message: q.Message,
+		Options: q.Options,
+	}
+
+	if q.Default != "" {
+		prompt.Default = q.Default
+	}
+
+	err = survey.AskOne(prompt, &ans, surveyValidator(q))
+	return
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// secretPlaceholder is the value of the secret fields on a `Template`.
+const secretPlaceholder = "<secret>"
+
+// Template returns a sample configuration file of the "dest" in the "format",
+// the "dest"'s values are written as they are, so a struct which holds the defaults produces a file with the defaults.
+// The "yaml" and "env" formats are commented with each field's "help" tag and whether it's required,
+// a field with a zero value is asked by `Load`, or a secret. Deprecated fields are omitted. Other registered formats, i.e "json", have no comments.
+// The values of the secret and sensitive fields, of any type, are replaced by "<secret>",
+// the keys of the formats without comments are sorted then.
+//
+// See the cmd/configgen tool too.
+func Template(dest interface{}, format string) ([]byte, error) {
+	v := reflect.Indirect(reflect.ValueOf(dest))
+	if v.Kind() != reflect.Struct {
+		return nil, ErrBad
+	}
+
+	buf := new(bytes.Buffer)
+
+	switch format = normalizeFormat(format); format {
+	case "yaml", "yml":
+		if err := writeYAMLTemplate(buf, v, field{}, ""); err != nil {
+			return nil, err
+		}
+	case "env":
+		// the struct elements of slices and maps are written field by field, i.e UPSTREAMS_0_HOST.
+		walkFields(v, "", defaultNaming, func(f field, fieldVal reflect.Value) {
+			if !f.Required || f.Deprecated != "" || isSectionList(fieldVal.Type()) {
+				return
+			}
+
+			writeComment(buf, "", f, fieldVal)
+			fmt.Fprintf(buf, "%s=%s\n", envName("", f), quoteIfNeeded(templateText(f, fieldVal), strconv.Quote))
+		})
+	default:
+		marshal, ok := encoders[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown configuration format: %q", format)
+		}
+
+		masked := false
+		walkFields(v, "", defaultNaming, func(f field, _ reflect.Value) {
+			masked = masked || f.Secret || f.Sensitive
+		})
+
+		value := v.Interface()
+		if masked {
+			// the placeholder is written from the file's tree, so it replaces the values of any type.
+			tree, err := maskedTree(v, format, func(field, reflect.Value) (interface{}, bool) {
+				return secretPlaceholder, true
+			})
+			if err != nil {
+				return nil, err
+			}
+			value = tree
+		}
+
+		b, err := marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeYAMLTemplate(buf *bytes.Buffer, v reflect.Value, parent field, indent string) error {
+	typ := v.Type()
+	for i, numField := 0, typ.NumField(); i < numField; i++ {
+		sf := typ.Field(i)
+		if sf.Type.Kind() == reflect.Ptr || sf.PkgPath != "" && !(sf.Anonymous && isSection(sf.Type)) || sf.Tag.Get("yaml") == "-" {
+			continue
+		}
+
+		key := fileNaming("yaml").fieldName(sf)
+		index, name := childPath(parent, i, key)
+		fieldVal := v.Field(i)
+
+		if isSection(sf.Type) {
+			if isInline(sf, fileNaming("yaml")) { // its keys are written to the parent, as the yaml decoder reads them.
+				if err := writeYAMLTemplate(buf, fieldVal, field{Name: parent.Name, Index: index, Prefix: parent.Prefix}, indent); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if help := sf.Tag.Get("help"); help != "" {
+				fmt.Fprintf(buf, "%s# %s\n", indent, help)
+			}
+			fmt.Fprintf(buf, "%s%s:\n", indent, key)
+
+			if err := writeYAMLTemplate(buf, fieldVal, field{Name: name, Index: index, Prefix: name + "."}, indent+"  "); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f := newField(sf, index, name)
+		if f.Deprecated != "" {
+			continue
+		}
+		writeComment(buf, indent, f, fieldVal)
+
+		var value interface{} // null for zero time values.
+		switch {
+		case f.Secret || f.Sensitive:
+			value = secretPlaceholder
+		case isSectionList(fieldVal.Type()):
+			// the secret and sensitive fields of the elements are replaced too.
+			tree, err := maskedTree(fieldVal, "yaml", func(field, reflect.Value) (interface{}, bool) {
+				return secretPlaceholder, true
+			})
+			if err != nil {
+				return err
+			}
+			value = tree
+		case fieldVal.Type() == timeType:
+			if !isZero(fieldVal) {
+				value = formatTime(fieldVal.Interface().(time.Time), f)
+			}
+		case fieldVal.CanInterface():
+			value = fieldVal.Interface()
+		}
+
+		b, err := yaml.Marshal(yaml.MapSlice{{Key: key, Value: value}})
+		if err != nil {
+			return err
+		}
+
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(string(b), "\n"), "\n") {
+			buf.WriteString(indent + strings.TrimSuffix(line, "\n") + "\n")
+		}
+	}
+
+	return nil
+}
+
+// writeComment writes the description of a field, if there is something to say about it.
+func writeComment(buf *bytes.Buffer, indent string, f field, fieldVal reflect.Value) {
+	var notes []string
+	if f.Required && isZero(fieldVal) {
+		notes = append(notes, "required")
+	}
+
+	if f.Secret {
+		notes = append(notes, "secret")
+	}
+
+	if f.Sensitive {
+		notes = append(notes, "sensitive")
+	}
+
+	if fieldVal.Type() == timeType {
+		notes = append(notes, "layout: "+timeLayout(f))
+	}
+
+	comment := f.Help
+	if len(notes) > 0 {
+		if comment != "" {
+			comment += " "
+		}
+		comment += "(" + strings.Join(notes, ", ") + ")"
+	}
+
+	if comment != "" {
+		fmt.Fprintf(buf, "%s# %s\n", indent, comment)
+	}
+}
+
+// templateText returns the text value of a field on a `Template`.
+func templateText(f field, fieldVal reflect.Value) string {
+	if f.Secret || f.Sensitive {
+		return secretPlaceholder
+	}
+
+	if isZero(fieldVal) || !fieldVal.CanInterface() {
+		return ""
+	}
+
+	return formatValue(f, fieldVal)
+}
+
+// Init asks for all the fields of the "dest" configuration, its values are the defaults,
+// and writes the answers to a new file, the "filename", in the format of its extension, i.e "config.yml".
+// The file's permissions are 0600 as it may contain secrets.
+//
+// It fails if the file already exists.
+// The "optional" are the `Load`'s options, i.e `WithPrompter` and `WithNaming` for the questions' names.
+func Init(filename string, dest interface{}, optional ...Option) error {
+	if !ok(dest) {
+		return ErrBad
+	}
+
+	format := normalizeFormat(filepath.Ext(filename))
+	marshal, found := encoders[format]
+	if !found {
+		return fmt.Errorf("unknown configuration format: %q", format)
+	}
+
+	if _, err := os.Stat(filename); err == nil {
+		return fmt.Errorf("init: %s: %w", filename, fs.ErrExist)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	opts := newOptions(optional)
+	if err := fieldConflicts(reflectType(dest), opts.naming); err != nil {
+		return err
+	}
+
+	if opts.prompter == nil {
+		return errors.New("init: a prompter is required")
+	}
+
+	if err := askAll(dest, opts); err != nil {
+		return err
+	}
+
+	if err := validateAndAsk(dest, opts); err != nil {
+		return err
+	}
+
+	b, err := marshal(dest)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(filename); dir != "." {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = file.Write(b); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
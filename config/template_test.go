@@ -0,0 +1,143 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+type testTemplateConfiguration struct {
+	Addr       string            `yaml:"Addr" help:"the address to listen on"`
+	ServerName string            `yaml:"ServerName"`
+	Legacy     string            `yaml:"Legacy" deprecated:"use ServerName"`
+	DB         testDBCredentials `yaml:"DB"`
+}
+
+func TestTemplate(t *testing.T) {
+	c := testTemplateConfiguration{ServerName: "my server", DB: testDBCredentials{Host: "localhost", Password: "123"}}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"yaml", `# the address to listen on (required)
+Addr: ""
+ServerName: my server
+DB:
+  # (required)
+  Username: ""
+  # (secret)
+  Password: <secret>
+  Host: localhost
+`},
+		{"env", `# the address to listen on (required)
+ADDR=""
+SERVERNAME="my server"
+# (required)
+DB_USERNAME=""
+# (secret)
+DB_PASSWORD="<secret>"
+DB_HOST=localhost
+`},
+	}
+
+	for _, tt := range tests {
+		b, err := Template(c, tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := string(b); tt.expected != got {
+			t.Fatalf("[%s] expected:\n%s\nbut got:\n%s", tt.format, tt.expected, got)
+		}
+	}
+
+	// the sample file should be loaded back.
+	b, _ := Template(c, "yaml")
+	var loaded testTemplateConfiguration
+	configtest.Load(t, "config.yml", &loaded, WithoutSurvey, WithFS(configtest.FS(map[string]string{"config.yml": string(b)})))
+	if expected, got := "<secret>", loaded.DB.Password; expected != got {
+		t.Fatalf("expected the secret placeholder %s but got: %s", expected, got)
+	}
+}
+
+func TestTemplateMaskedFields(t *testing.T) {
+	c := struct {
+		Addr string
+		Port int `config:"sensitive"`
+		PIN  int `config:"secret"`
+	}{Addr: ":8080", Port: 8080, PIN: 1234}
+
+	for format, expected := range map[string]string{
+		"json": "{\n  \"Addr\": \":8080\",\n  \"PIN\": \"<secret>\",\n  \"Port\": \"<secret>\"\n}\n",
+		"toml": "Addr = \":8080\"\nPIN = \"<secret>\"\nPort = \"<secret>\"\n",
+	} {
+		b, err := Template(c, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := string(b); expected != got {
+			t.Fatalf("[%s] expected:\n%s\nbut got:\n%s", format, expected, got)
+		}
+	}
+}
+
+func TestTemplateSectionLists(t *testing.T) {
+	type upstream struct {
+		Host  string `yaml:"host"`
+		Token string `yaml:"token" config:"secret"`
+	}
+
+	c := struct {
+		Upstreams []upstream `yaml:"upstreams"`
+	}{Upstreams: []upstream{{Host: "a.local", Token: "TOPSECRET"}}}
+
+	for format, expected := range map[string]string{
+		"yaml": "upstreams:\n- host: a.local\n  token: <secret>\n",
+		"json": "{\n  \"Upstreams\": [\n    {\n      \"Host\": \"a.local\",\n      \"Token\": \"<secret>\"\n    }\n  ]\n}\n",
+		"env":  "UPSTREAMS_0_HOST=a.local\n# (secret)\nUPSTREAMS_0_TOKEN=\"<secret>\"\n",
+	} {
+		b, err := Template(c, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := string(b); expected != got {
+			t.Fatalf("[%s] expected:\n%s\nbut got:\n%s", format, expected, got)
+		}
+	}
+}
+
+func TestInit(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	answers := configtest.Answers(map[string]string{
+		"Addr":        ":8080",
+		"ServerName":  "", // keep the default.
+		"DB.Username": "kataras",
+		"DB.Password": "123",
+		"DB.Host":     "localhost",
+	})
+
+	c := testTemplateConfiguration{ServerName: "my server"}
+	if err := Init(filename, &c, WithPrompter(answers)); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Addr: :8080\nServerName: my server\nLegacy: \"\"\nDB:\n  Username: kataras\n  Password: \"123\"\n  Host: localhost\n"
+	if got := string(b); expected != got {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	if err = Init(filename, &c, WithPrompter(answers)); err == nil {
+		t.Fatalf("expected an error as the file already exists")
+	}
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// TimeLayout is the layout that is used
+// when a field is a time.Time type and it's required.
+// the user type a time in string, and in order to this
+// string to be converted to a time.Time and be set-ed
+// to the setting field it needs to have a known layout.
+//
+// A field can use its own layout through the "layout" tag, i.e `layout:"2006-01-02"`,
+// and its own time zone through the "tz" tag, i.e `tz:"Europe/Athens"`.
+// If the value can't be parsed with the layout then
+// the RFC3339 format and a Unix timestamp (in seconds) are tried as well.
+//
+// Note that these are used on flags and prompts,
+// the file decoder parses the file's time values on its own.
+//
+// Defaults to "Mon, 02 Jan 2006 15:04:05 GMT".
+var TimeLayout = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+func timeLayout(f field) string {
+	if f.Layout != "" {
+		return f.Layout
+	}
+
+	return TimeLayout
+}
+
+// timeLocation returns the location of the field's "tz" tag, defaults to UTC.
+func timeLocation(f field) (*time.Location, error) {
+	if f.TZ == "" {
+		return time.UTC, nil
+	}
+
+	return time.LoadLocation(f.TZ)
+}
+
+// parseTime parses a time value of a field based on its layout and time zone,
+// it falls back to RFC3339 and Unix timestamps.
+func parseTime(got string, f field) (time.Time, error) {
+	loc, err := timeLocation(f)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	layout := timeLayout(f)
+	if t, err := time.ParseInLocation(layout, got, loc); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, got); err == nil {
+		return t.In(loc), nil
+	}
+
+	if sec, err := strconv.ParseInt(got, 10, 64); err == nil {
+		return time.Unix(sec, 0).In(loc), nil
+	}
+
+	return time.Time{}, fmt.Errorf("expected date/time of layout '%s', RFC3339 or Unix timestamp, got '%s'", layout, maskValue(f, got))
+}
+
+// formatTime formats a time value of a field based on its layout and time zone.
+func formatTime(t time.Time, f field) string {
+	if loc, err := timeLocation(f); err == nil {
+		t = t.In(loc)
+	}
+
+	return t.Format(timeLayout(f))
+}
+
+// decodeJSON is the `FileDecoder` of the "json" format, it's the `json.Unmarshal`
+// but the string values of the time.Duration fields, i.e "30s", are accepted too,
+// they are converted to nanoseconds, the only duration's value that the json decoder knows.
+func decodeJSON(data []byte, v interface{}) error {
+	typ := reflect.TypeOf(v)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return json.Unmarshal(data, v)
+	}
+
+	// the numbers are kept as they are written, so the integers above 2^53 don't lose their precision.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var raw interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return json.Unmarshal(data, v) // the decoder's error.
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return json.Unmarshal(data, v) // i.e invalid characters after the top-level value.
+	}
+
+	raw, converted := convertDurations(typ.Elem(), raw)
+	if !converted {
+		// decode the original contents, so the errors' offsets point to the file's lines.
+		return json.Unmarshal(data, v)
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// convertDurations converts the duration strings of a generic json tree to nanoseconds,
+// based on the fields of the "typ", and reports whether any of them was converted.
+func convertDurations(typ reflect.Type, v interface{}) (interface{}, bool) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == durationType {
+		if s, ok := v.(string); ok {
+			if d, err := time.ParseDuration(s); err == nil {
+				return int64(d), true
+			}
+		}
+
+		return v, false // let the json decoder fail with its own error.
+	}
+
+	converted := false
+	switch typ.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		n := naming{tag: "json"}
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			if sf.Anonymous && sf.Tag.Get("json") == "" && sf.Type.Kind() == reflect.Struct {
+				// the json decoder promotes the fields of the embedded structs.
+				_, ok := convertDurations(sf.Type, m)
+				converted = converted || ok
+				continue
+			}
+
+			if sf.PkgPath != "" || sf.Tag.Get("json") == "-" {
+				continue
+			}
+
+			key, ok := findKey(m, n.fieldName(sf))
+			if !ok {
+				continue
+			}
+
+			if m[key], ok = convertDurations(sf.Type, m[key]); ok {
+				converted = true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if list, ok := v.([]interface{}); ok {
+			for i := range list {
+				if list[i], ok = convertDurations(typ.Elem(), list[i]); ok {
+					converted = true
+				}
+			}
+		}
+	case reflect.Map:
+		if m, ok := v.(map[string]interface{}); ok {
+			for key := range m {
+				if m[key], ok = convertDurations(typ.Elem(), m[key]); ok {
+					converted = true
+				}
+			}
+		}
+	}
+
+	return v, converted
+}
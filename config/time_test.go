@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+type testTimeConfiguration struct {
+	Timeout   time.Duration `yaml:"Timeout"`
+	Interval  time.Duration `yaml:"Interval"`
+	Birthday  time.Time     `yaml:"Birthday" layout:"2006-01-02"`
+	StartedAt time.Time     `yaml:"StartedAt" tz:"Europe/Athens"`
+	ExpiresAt time.Time     `yaml:"ExpiresAt"`
+}
+
+func TestLoadTime(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": "Timeout: 30s"})
+	answers := configtest.Answers(map[string]string{
+		"Birthday":  "1993-10-26",
+		"StartedAt": "2019-11-08T10:00:00Z",
+	})
+
+	var c testTimeConfiguration
+	configtest.Load(t, "config.yml", &c,
+		WithFS(fsys),
+		WithFlags(configtest.Flags("-interval", "1h30m", "-expiresat", "1573207200")),
+		WithPrompter(answers))
+
+	athens, err := time.LoadLocation("Europe/Athens")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	configtest.AssertLoaded(t, c, testTimeConfiguration{
+		Timeout:   30 * time.Second,
+		Interval:  90 * time.Minute,
+		Birthday:  time.Date(1993, 10, 26, 0, 0, 0, 0, time.UTC),
+		StartedAt: time.Date(2019, 11, 8, 12, 0, 0, 0, athens),
+		ExpiresAt: time.Unix(1573207200, 0).UTC(),
+	})
+}
+
+func TestLoadReaderJSONDuration(t *testing.T) {
+	type upstream struct {
+		Timeout time.Duration `json:"timeout"`
+	}
+
+	var c struct {
+		testTimeConfiguration
+		Upstreams []upstream `json:"upstreams"`
+	}
+	configtest.LoadReader(t, strings.NewReader(`{"Timeout": "30s", "interval": 5000000000, "upstreams": [{"timeout": "1m"}]}`), "json", &c, WithoutSurvey)
+
+	if expected, got := 30*time.Second, c.Timeout; expected != got {
+		t.Fatalf("expected timeout to be %s but got: %s", expected, got)
+	}
+
+	if expected, got := 5*time.Second, c.Interval; expected != got {
+		t.Fatalf("expected interval to be %s but got: %s", expected, got)
+	}
+
+	if expected, got := time.Minute, c.Upstreams[0].Timeout; expected != got {
+		t.Fatalf("expected the upstream's timeout to be %s but got: %s", expected, got)
+	}
+}
+
+func TestLoadReaderJSONDurationPrecision(t *testing.T) {
+	var c struct {
+		Timeout time.Duration
+		ID      int64
+	}
+	configtest.LoadReader(t, strings.NewReader(`{"Timeout": "30s", "ID": 9007199254740993}`), "json", &c, WithoutSurvey)
+
+	if expected, got := int64(9007199254740993), c.ID; expected != got {
+		t.Fatalf("expected id to be %d but got: %d", expected, got)
+	}
+}
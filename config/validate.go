@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validator can be implemented by a configuration struct, or any of its nested structs,
+// to check its fields against each other, i.e "TLSCert and TLSKey must both be set".
+// `Load` calls it after all the sources were loaded.
+//
+// See `InvalidFields` too.
+type Validator interface {
+	Validate() error
+}
+
+// FieldsError is an error of a `Validator` which knows the offending fields,
+// see `InvalidFields`.
+type FieldsError struct {
+	// Fields are the names of the struct's fields, i.e "TLSKey", or their configuration names.
+	Fields []string
+	Err    error
+}
+
+// InvalidFields returns an error which marks the "fields" of the struct as invalid,
+// a `Validator` should return it, so `Load` can ask for these fields' values again when survey is enabled.
+// The "fields" can be the Go names of the struct's fields or their configuration names.
+func InvalidFields(err error, fields ...string) error {
+	return &FieldsError{Fields: fields, Err: err}
+}
+
+func (e *FieldsError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldsError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is the error of a struct's `Validator`.
+type ValidationError struct {
+	// Path is the struct's full name, i.e "DBCredentials", empty for the configuration itself.
+	Path string
+	// Fields are the full names of the offending fields, if known, i.e "DBCredentials.Password".
+	Fields []string
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is returned by `Load` when any `Validator` failed.
+type ValidationErrors struct {
+	Errors []*ValidationError
+}
+
+func (e *ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+// MaxValidationRounds is the number of times that `Load` asks
+// for the offending fields of a failed `Validator` before it gives up.
+var MaxValidationRounds = 3
+
+// Validate calls the `Validator` of the "dest" configuration and of its nested structs, if they implement it.
+// Returns a *ValidationErrors if any of them failed, otherwise nil.
+func Validate(dest interface{}) error {
+	if !ok(dest) {
+		return ErrBad
+	}
+
+	if errs := runValidators(dest, defaultNaming); len(errs) > 0 {
+		return &ValidationErrors{Errors: errs}
+	}
+
+	return nil
+}
+
+func runValidators(dest interface{}, n naming) (errs []*ValidationError) {
+	walkSections(reflect.ValueOf(dest).Elem(), "", n, func(section field, sectionVal reflect.Value) {
+		if !sectionVal.CanAddr() || !sectionVal.Addr().CanInterface() {
+			return
+		}
+
+		validator, ok := sectionVal.Addr().Interface().(Validator)
+		if !ok {
+			return
+		}
+
+		err := validator.Validate()
+		if err == nil {
+			return
+		}
+
+		verr := &ValidationError{Path: section.Name, Err: err}
+
+		var fieldsErr *FieldsError
+		if errors.As(err, &fieldsErr) {
+			for _, fieldName := range fieldsErr.Fields {
+				verr.Fields = append(verr.Fields, resolveFieldName(sectionVal.Type(), section.Prefix, fieldName, n))
+			}
+		}
+
+		errs = append(errs, verr)
+	})
+
+	return
+}
+
+// resolveFieldName returns the full configuration name of a struct's field by its Go or configuration name,
+// the "prefix" is the section's one, i.e "Server.".
+func resolveFieldName(typ reflect.Type, prefix, name string, n naming) string {
+	if f, ok := typ.FieldByName(name); ok {
+		name = n.fieldName(f)
+	}
+
+	return prefix + name
+}
+
+// validateAndAsk validates the "dest" and, when survey is enabled,
+// asks again for the offending fields until they are valid or the rounds are over.
+func validateAndAsk(dest interface{}, opts options) error {
+	for round := 0; ; round++ {
+		errs := runValidators(dest, opts.naming)
+		if len(errs) == 0 {
+			return nil
+		}
+
+		if opts.disableSurvey || opts.prompter == nil || round >= MaxValidationRounds || !reaskable(errs) {
+			return &ValidationErrors{Errors: errs}
+		}
+
+		var err error
+		opts.report.record(dest, opts.naming, SourcePrompt, func() {
+			err = reask(dest, errs, opts)
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func reaskable(errs []*ValidationError) bool {
+	for _, err := range errs {
+		if len(err.Fields) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reask asks for the offending fields' values, even if they are not zero.
+func reask(dest interface{}, errs []*ValidationError, opts options) error {
+	var askErr error
+	for _, err := range errs {
+		for _, name := range err.Fields {
+			walkFields(reflect.ValueOf(dest).Elem(), "", opts.naming, func(f field, fValue reflect.Value) {
+				if askErr != nil || f.Name != name || !f.Required || isSectionList(fValue.Type()) {
+					return
+				}
+
+				askErr = askField(f, fValue, fmt.Sprintf("invalid: %v", err.Err), opts)
+			})
+
+			if askErr != nil {
+				return askErr
+			}
+		}
+	}
+
+	return nil
+}
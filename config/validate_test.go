@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/kataras/pkg/config"
+	"github.com/kataras/pkg/config/configtest"
+)
+
+type testTLS struct {
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+}
+
+func (tls testTLS) Validate() error {
+	if (tls.Cert == "") != (tls.Key == "") {
+		return InvalidFields(errors.New("cert and key must both be set"), "Cert", "Key")
+	}
+
+	return nil
+}
+
+type testValidateConfiguration struct {
+	MinConns int     `yaml:"MinConns"`
+	MaxConns int     `yaml:"MaxConns"`
+	TLS      testTLS `yaml:"TLS"`
+}
+
+func (c *testValidateConfiguration) Validate() error {
+	if c.MaxConns < c.MinConns {
+		return InvalidFields(errors.New("MaxConns should be greater than or equal to MinConns"), "MaxConns")
+	}
+
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	c := testValidateConfiguration{MinConns: 10, MaxConns: 5, TLS: testTLS{Cert: "cert.pem"}}
+
+	err := Validate(&c)
+
+	var verrs *ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected validation errors but got: %v", err)
+	}
+
+	if expected, got := 2, len(verrs.Errors); expected != got {
+		t.Fatalf("expected %d validation errors but got %d: %v", expected, got, err)
+	}
+
+	if expected, got := "TLS", verrs.Errors[1].Path; expected != got {
+		t.Fatalf("expected path %s but got: %s", expected, got)
+	}
+
+	if expected, got := "TLS.key", verrs.Errors[1].Fields[1]; expected != got {
+		t.Fatalf("expected field %s but got: %s", expected, got)
+	}
+}
+
+func TestLoadValidateAsk(t *testing.T) {
+	fsys := configtest.FS(map[string]string{"config.yml": "MinConns: 10\nMaxConns: 5"})
+	answers := configtest.Answers(map[string]string{"MaxConns": "20"})
+
+	var c testValidateConfiguration
+	report := configtest.Load(t, "config.yml", &c, WithFS(fsys), WithPrompter(answers))
+
+	if expected, got := 20, c.MaxConns; expected != got {
+		t.Fatalf("expected MaxConns to be asked again and set to %d but got: %d", expected, got)
+	}
+
+	configtest.AssertSource(t, report, "MaxConns", "prompt")
+
+	c = testValidateConfiguration{}
+	err := Load("config.yml", &c, WithFS(fsys), WithoutSurvey)
+
+	var verr *ValidationErrors
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected validation errors but got: %v", err)
+	}
+}